@@ -2,10 +2,10 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/f5/f5xcctl/internal/cmd"
+	"github.com/f5/f5xcctl/internal/output"
 )
 
 // Version information set by build flags.
@@ -18,7 +18,7 @@ var (
 func main() {
 	cmd.SetVersionInfo(version, commit, date)
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
+		output.Errorf("%v", err)
 		os.Exit(1)
 	}
 }
@@ -7,14 +7,19 @@ import (
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 // TokenResponse represents an OAuth token response.
@@ -24,6 +29,9 @@ type TokenResponse struct {
 	ExpiresIn    int       `json:"expires_in"`
 	RefreshToken string    `json:"refresh_token,omitempty"`
 	ExpiresAt    time.Time `json:"-"`
+	// CredentialName names the API credential created by a token exchange,
+	// which is revoked once the token is replaced.
+	CredentialName string `json:"-"`
 }
 
 // Authenticator interface for different auth methods.
@@ -34,6 +42,27 @@ type Authenticator interface {
 	GetHTTPClient() (*http.Client, error)
 }
 
+// PasswordFunc prompts the user for a secret and returns the entered value.
+type PasswordFunc func(prompt string) (string, error)
+
+// ReadSecretEnv returns the value of the environment variable name, or the
+// contents of the file named by name+"_FILE" when the variable itself is unset.
+// Trailing newlines are stripped from file contents.
+func ReadSecretEnv(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
 // TokenAuth implements authentication using an API token.
 type TokenAuth struct {
 	Token string
@@ -63,13 +92,22 @@ func (a *TokenAuth) GetHTTPClient() (*http.Client, error) {
 type CertAuth struct {
 	CertFile string
 	KeyFile  string
+	// KeyPassword decrypts an encrypted private key.
+	KeyPassword string
+	// PasswordPrompt is called when the key is encrypted and no KeyPassword is set.
+	PasswordPrompt PasswordFunc
+	// ExpiryWarningWindow controls how early expiry warnings are emitted.
+	ExpiryWarningWindow time.Duration
+	// WarningWriter receives expiry warnings (defaults to os.Stderr).
+	WarningWriter io.Writer
 }
 
 // NewCertAuth creates a new certificate authenticator.
 func NewCertAuth(certFile, keyFile string) *CertAuth {
 	return &CertAuth{
-		CertFile: certFile,
-		KeyFile:  keyFile,
+		CertFile:            certFile,
+		KeyFile:             keyFile,
+		ExpiryWarningWindow: DefaultExpiryWarningWindow,
 	}
 }
 
@@ -80,13 +118,17 @@ func (a *CertAuth) GetToken() (string, error) {
 
 // GetHTTPClient returns an HTTP client configured with the client certificate.
 func (a *CertAuth) GetHTTPClient() (*http.Client, error) {
-	cert, err := tls.LoadX509KeyPair(a.CertFile, a.KeyFile)
+	cert, err := a.loadCertificate()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load certificate: %w", err)
 	}
 
+	if err := CheckCertificateExpiry(cert, a.ExpiryWarningWindow, a.WarningWriter); err != nil {
+		return nil, err
+	}
+
 	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
+		Certificates: []tls.Certificate{*cert},
 		MinVersion:   tls.VersionTLS12,
 	}
 
@@ -100,18 +142,61 @@ func (a *CertAuth) GetHTTPClient() (*http.Client, error) {
 	}, nil
 }
 
+// loadCertificate loads the PEM certificate and key, decrypting the key if needed.
+func (a *CertAuth) loadCertificate() (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(a.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(a.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsEncryptedPEMKey(keyPEM) {
+		password := a.KeyPassword
+		if password == "" && a.PasswordPrompt != nil {
+			password, err = a.PasswordPrompt(fmt.Sprintf("Passphrase for %s: ", a.KeyFile))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read passphrase: %w", err)
+			}
+		}
+		if password == "" {
+			return nil, fmt.Errorf("private key %s is encrypted: set key-password in credentials or F5XC_KEY_PASSWORD", a.KeyFile)
+		}
+		keyPEM, err = DecryptPEMKey(keyPEM, password)
+		if err != nil {
+			return nil, err
+		}
+		a.KeyPassword = password
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
 // P12Auth implements authentication using PKCS#12 certificate bundles.
 type P12Auth struct {
 	P12File  string
 	Password string
-	cert     *tls.Certificate // cached parsed certificate
+	// PasswordPrompt is called when the bundle needs a password and none is set.
+	PasswordPrompt PasswordFunc
+	// ExpiryWarningWindow controls how early expiry warnings are emitted.
+	ExpiryWarningWindow time.Duration
+	// WarningWriter receives expiry warnings (defaults to os.Stderr).
+	WarningWriter io.Writer
+	cert          *tls.Certificate // cached parsed certificate
 }
 
 // NewP12Auth creates a new P12 authenticator.
 func NewP12Auth(p12File, password string) *P12Auth {
 	return &P12Auth{
-		P12File:  p12File,
-		Password: password,
+		P12File:             p12File,
+		Password:            password,
+		ExpiryWarningWindow: DefaultExpiryWarningWindow,
 	}
 }
 
@@ -127,6 +212,10 @@ func (a *P12Auth) GetHTTPClient() (*http.Client, error) {
 		return nil, err
 	}
 
+	if err := CheckCertificateExpiry(cert, a.ExpiryWarningWindow, a.WarningWriter); err != nil {
+		return nil, err
+	}
+
 	tlsConfig := &tls.Config{
 		Certificates: []tls.Certificate{*cert},
 		MinVersion:   tls.VersionTLS12,
@@ -142,6 +231,12 @@ func (a *P12Auth) GetHTTPClient() (*http.Client, error) {
 	}, nil
 }
 
+// Certificate returns the parsed client certificate, prompting for the
+// password if required.
+func (a *P12Auth) Certificate() (*tls.Certificate, error) {
+	return a.loadCertificate()
+}
+
 // loadCertificate loads and parses the P12 file.
 func (a *P12Auth) loadCertificate() (*tls.Certificate, error) {
 	if a.cert != nil {
@@ -149,6 +244,19 @@ func (a *P12Auth) loadCertificate() (*tls.Certificate, error) {
 	}
 
 	cert, err := LoadP12Certificate(a.P12File, a.Password)
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) && a.Password == "" {
+		if a.PasswordPrompt == nil {
+			return nil, fmt.Errorf("P12 file %s is password protected: set p12-password in credentials, F5XC_P12_PASSWORD or F5XC_P12_PASSWORD_FILE", a.P12File)
+		}
+		password, promptErr := a.PasswordPrompt(fmt.Sprintf("Password for %s: ", a.P12File))
+		if promptErr != nil {
+			return nil, fmt.Errorf("failed to read P12 password: %w", promptErr)
+		}
+		cert, err = LoadP12Certificate(a.P12File, password)
+		if err == nil {
+			a.Password = password
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
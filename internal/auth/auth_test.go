@@ -1,19 +1,31 @@
 package auth
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/pbkdf2"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 func TestNewTokenAuth(t *testing.T) {
@@ -106,6 +118,452 @@ func TestCertAuth_GetHTTPClient_InvalidCert(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to load certificate")
 }
 
+func TestCertAuth_GetHTTPClient_Expired(t *testing.T) {
+	certFile, keyFile, cleanup := createTestCertificateWithValidity(t, time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour))
+	defer cleanup()
+
+	auth := NewCertAuth(certFile, keyFile)
+	client, err := auth.GetHTTPClient()
+
+	assert.Error(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, err.Error(), "expired on")
+}
+
+func TestCertAuth_GetHTTPClient_ExpiryWarning(t *testing.T) {
+	tests := []struct {
+		name     string
+		notAfter time.Duration
+		window   time.Duration
+		wantWarn bool
+	}{
+		{
+			name:     "expires within window",
+			notAfter: 10 * 24 * time.Hour,
+			window:   DefaultExpiryWarningWindow,
+			wantWarn: true,
+		},
+		{
+			name:     "expires after window",
+			notAfter: 60 * 24 * time.Hour,
+			window:   DefaultExpiryWarningWindow,
+			wantWarn: false,
+		},
+		{
+			name:     "warnings disabled",
+			notAfter: 10 * 24 * time.Hour,
+			window:   -1,
+			wantWarn: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certFile, keyFile, cleanup := createTestCertificateWithValidity(t, time.Now().Add(-time.Hour), time.Now().Add(tt.notAfter))
+			defer cleanup()
+
+			var warnings bytes.Buffer
+			auth := NewCertAuth(certFile, keyFile)
+			auth.ExpiryWarningWindow = tt.window
+			auth.WarningWriter = &warnings
+
+			client, err := auth.GetHTTPClient()
+			require.NoError(t, err)
+			assert.NotNil(t, client)
+
+			if tt.wantWarn {
+				assert.Contains(t, warnings.String(), "expires in")
+			} else {
+				assert.Empty(t, warnings.String())
+			}
+		})
+	}
+}
+
+func TestNewCertInfo(t *testing.T) {
+	certFile, _, cleanup := createTestCertificate(t)
+	defer cleanup()
+
+	leaf, err := ReadPEMCertificate(certFile)
+	require.NoError(t, err)
+
+	info := NewCertInfo(leaf)
+	assert.Contains(t, info.Subject, "CN=test.example.com")
+	assert.Equal(t, "01", info.SerialNumber)
+	assert.False(t, info.IsExpired())
+	assert.False(t, info.IsNotYetValid())
+	assert.Greater(t, info.ExpiresIn(), time.Duration(0))
+}
+
+func TestReadPEMCertificate_NoCertificate(t *testing.T) {
+	_, keyFile, cleanup := createTestCertificate(t)
+	defer cleanup()
+
+	_, err := ReadPEMCertificate(keyFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no certificate found")
+}
+
+func TestCertAuth_EncryptedKey(t *testing.T) {
+	certFile, keyFile, cleanup := createTestCertificate(t)
+	defer cleanup()
+	encryptTestKey(t, keyFile, "s3cret")
+
+	t.Run("passphrase configured", func(t *testing.T) {
+		auth := NewCertAuth(certFile, keyFile)
+		auth.KeyPassword = "s3cret"
+		client, err := auth.GetHTTPClient()
+		require.NoError(t, err)
+		assert.NotNil(t, client.Transport)
+	})
+
+	t.Run("passphrase prompted", func(t *testing.T) {
+		var prompted string
+		auth := NewCertAuth(certFile, keyFile)
+		auth.PasswordPrompt = func(prompt string) (string, error) {
+			prompted = prompt
+			return "s3cret", nil
+		}
+		_, err := auth.GetHTTPClient()
+		require.NoError(t, err)
+		assert.Contains(t, prompted, keyFile)
+		assert.Equal(t, "s3cret", auth.KeyPassword)
+	})
+
+	t.Run("passphrase missing", func(t *testing.T) {
+		auth := NewCertAuth(certFile, keyFile)
+		_, err := auth.GetHTTPClient()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is encrypted")
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		auth := NewCertAuth(certFile, keyFile)
+		auth.KeyPassword = "wrong"
+		_, err := auth.GetHTTPClient()
+		assert.ErrorIs(t, err, ErrIncorrectPassphrase)
+	})
+}
+
+func TestDecryptPEMKey_IterationCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		iterations int
+	}{
+		{"zero", 0},
+		{"negative", -1},
+		{"too large", maxKeyIterations + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, keyFile, cleanup := createTestCertificate(t)
+			defer cleanup()
+			encryptTestKeyWithIterations(t, keyFile, "pass", tt.iterations)
+
+			keyPEM, err := os.ReadFile(keyFile)
+			require.NoError(t, err)
+			_, err = DecryptPEMKey(keyPEM, "pass")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unsupported PBKDF2 iteration count")
+		})
+	}
+}
+
+func TestIsEncryptedPEMKey(t *testing.T) {
+	_, keyFile, cleanup := createTestCertificate(t)
+	defer cleanup()
+
+	keyPEM, err := os.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.False(t, IsEncryptedPEMKey(keyPEM))
+
+	encryptTestKey(t, keyFile, "pass")
+	keyPEM, err = os.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedPEMKey(keyPEM))
+}
+
+func TestP12Auth_PasswordPrompt(t *testing.T) {
+	p12File := createTestP12(t, "p12-pass")
+
+	t.Run("password configured", func(t *testing.T) {
+		auth := NewP12Auth(p12File, "p12-pass")
+		cert, err := auth.Certificate()
+		require.NoError(t, err)
+		assert.NotNil(t, cert.Leaf)
+	})
+
+	t.Run("password prompted", func(t *testing.T) {
+		calls := 0
+		auth := NewP12Auth(p12File, "")
+		auth.PasswordPrompt = func(prompt string) (string, error) {
+			calls++
+			return "p12-pass", nil
+		}
+		_, err := auth.Certificate()
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "p12-pass", auth.Password)
+	})
+
+	t.Run("password missing without prompt", func(t *testing.T) {
+		auth := NewP12Auth(p12File, "")
+		_, err := auth.Certificate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "F5XC_P12_PASSWORD_FILE")
+	})
+
+	t.Run("prompt error", func(t *testing.T) {
+		auth := NewP12Auth(p12File, "")
+		auth.PasswordPrompt = func(prompt string) (string, error) {
+			return "", errors.New("no tty")
+		}
+		_, err := auth.Certificate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no tty")
+	})
+}
+
+func TestReadSecretEnv(t *testing.T) {
+	t.Setenv("F5XC_TEST_SECRET", "")
+	t.Setenv("F5XC_TEST_SECRET_FILE", "")
+
+	value, err := ReadSecretEnv("F5XC_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+	t.Setenv("F5XC_TEST_SECRET_FILE", secretFile)
+
+	value, err = ReadSecretEnv("F5XC_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", value)
+
+	t.Setenv("F5XC_TEST_SECRET", "from-env")
+	value, err = ReadSecretEnv("F5XC_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+
+	t.Setenv("F5XC_TEST_SECRET", "")
+	t.Setenv("F5XC_TEST_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = ReadSecretEnv("F5XC_TEST_SECRET")
+	assert.Error(t, err)
+}
+
+// memoryTokenCache is an in-memory TokenCache for tests.
+type memoryTokenCache struct {
+	token *TokenResponse
+	saves int
+	err   error
+}
+
+func (c *memoryTokenCache) Load() (*TokenResponse, error) { return c.token, nil }
+
+func (c *memoryTokenCache) Save(token *TokenResponse) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.token = token
+	c.saves++
+	return nil
+}
+
+func TestExchangeAuth(t *testing.T) {
+	// The server tracks the API credentials that are still active.
+	requests := 0
+	active := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		name, _ := body["name"].(string)
+		assert.Equal(t, "system", body["namespace"])
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/web/namespaces/system/api_credentials":
+			requests++
+			spec, _ := body["spec"].(map[string]interface{})
+			assert.Equal(t, "API_TOKEN", spec["type"])
+			assert.False(t, active[name], "credential name %q reused", name)
+			active[name] = true
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":                 name,
+				"data":                 "session-token",
+				"expiration_timestamp": time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			})
+		case "/api/web/namespaces/system/revoke/api_credentials":
+			assert.True(t, active[name], "revoked unknown credential %q", name)
+			delete(active, name)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": true})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cache := &memoryTokenCache{}
+	exchange := NewExchangeAuth(NewTokenAuth(""), server.URL+"/", cache)
+
+	client, err := exchange.GetHTTPClient()
+	require.NoError(t, err)
+	assert.Nil(t, client.Transport)
+
+	token, err := exchange.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "session-token", token)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 1, cache.saves)
+	first := cache.token.CredentialName
+	assert.True(t, active[first])
+
+	// A fresh authenticator reuses the cached token without exchanging.
+	reused := NewExchangeAuth(NewTokenAuth(""), server.URL, cache)
+	token, err = reused.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "session-token", token)
+	assert.Equal(t, 1, requests)
+
+	// An expiring cached token triggers a new exchange, which revokes the
+	// credential of the old token.
+	cache.token.ExpiresAt = time.Now().Add(time.Minute)
+	expired := NewExchangeAuth(NewTokenAuth(""), server.URL, cache)
+	_, err = expired.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 2, cache.saves)
+	assert.NotEqual(t, first, cache.token.CredentialName)
+	assert.Equal(t, map[string]bool{cache.token.CredentialName: true}, active)
+
+	// A credential that cannot be cached is revoked right away
+	cache.token.ExpiresAt = time.Now().Add(time.Minute)
+	cache.err = errors.New("disk full")
+	failed := NewExchangeAuth(NewTokenAuth(""), server.URL, cache)
+	_, err = failed.GetToken()
+	require.Error(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, map[string]bool{cache.token.CredentialName: true}, active)
+}
+
+func TestExchangeAuth_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	exchange := NewExchangeAuth(NewTokenAuth(""), server.URL, nil)
+	_, err := exchange.GetToken()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status 403")
+}
+
+// newStubTokenServer starts a local RFC 8693 token-exchange endpoint that
+// accepts wantJWT and counts the exchanges it performs.
+func newStubTokenServer(t *testing.T, wantJWT string, exchanges *int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, grantTypeTokenExchange, r.PostForm.Get("grant_type"))
+		assert.Equal(t, tokenTypeJWT, r.PostForm.Get("subject_token_type"))
+		assert.Equal(t, "f5xc", r.PostForm.Get("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("subject_token") != wantJWT {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "subject token rejected",
+			})
+			return
+		}
+
+		*exchanges++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "f5xc-token",
+			"token_type":   "APIToken",
+			"expires_in":   3600,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCFederationAuth_TokenFile(t *testing.T) {
+	exchanges := 0
+	server := newStubTokenServer(t, "ci.jwt.value", &exchanges)
+
+	tokenFile := filepath.Join(t.TempDir(), "oidc-token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("ci.jwt.value\n"), 0o600))
+
+	oidc := NewOIDCFederationAuth(tokenFile, "", server.URL, "f5xc")
+	client, err := oidc.GetHTTPClient()
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+
+	token, err := oidc.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "f5xc-token", token)
+	assert.Equal(t, 1, exchanges, "token should be reused until it expires")
+}
+
+func TestOIDCFederationAuth_TokenEnv(t *testing.T) {
+	exchanges := 0
+	server := newStubTokenServer(t, "env.jwt.value", &exchanges)
+	t.Setenv("CI_JOB_JWT_V2", "env.jwt.value")
+
+	oidc := NewOIDCFederationAuth("", "CI_JOB_JWT_V2", server.URL, "f5xc")
+	token, err := oidc.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "f5xc-token", token)
+}
+
+func TestOIDCFederationAuth_Errors(t *testing.T) {
+	exchanges := 0
+	server := newStubTokenServer(t, "expected.jwt", &exchanges)
+	t.Setenv("F5XC_TEST_EMPTY_JWT", "")
+	t.Setenv("F5XC_TEST_WRONG_JWT", "wrong.jwt")
+
+	tests := []struct {
+		name    string
+		auth    *OIDCFederationAuth
+		wantErr string
+	}{
+		{
+			name:    "no exchange url",
+			auth:    NewOIDCFederationAuth("", "F5XC_TEST_WRONG_JWT", "", ""),
+			wantErr: "no OIDC token exchange URL",
+		},
+		{
+			name:    "no token source",
+			auth:    NewOIDCFederationAuth("", "", server.URL, "f5xc"),
+			wantErr: "no OIDC token source",
+		},
+		{
+			name:    "empty env var",
+			auth:    NewOIDCFederationAuth("", "F5XC_TEST_EMPTY_JWT", server.URL, "f5xc"),
+			wantErr: "is not set",
+		},
+		{
+			name:    "rejected token",
+			auth:    NewOIDCFederationAuth("", "F5XC_TEST_WRONG_JWT", server.URL, "f5xc"),
+			wantErr: "invalid_grant",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.auth.GetToken()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+	assert.Equal(t, 0, exchanges)
+}
+
 func TestNewBrowserAuth(t *testing.T) {
 	auth := NewBrowserAuth("my-tenant", "https://api.example.com")
 	assert.NotNil(t, auth)
@@ -149,11 +607,20 @@ func TestAuthenticatorInterface(t *testing.T) {
 	// Verify both auth types implement Authenticator interface
 	var _ Authenticator = (*TokenAuth)(nil)
 	var _ Authenticator = (*CertAuth)(nil)
+	var _ Authenticator = (*P12Auth)(nil)
+	var _ Authenticator = (*ExchangeAuth)(nil)
+	var _ Authenticator = (*OIDCFederationAuth)(nil)
 }
 
 // Helper function to create a test certificate and key.
 func createTestCertificate(t *testing.T) (certFile, keyFile string, cleanup func()) {
 	t.Helper()
+	return createTestCertificateWithValidity(t, time.Now(), time.Now().Add(90*24*time.Hour))
+}
+
+// Helper function to create a test certificate and key with a given validity period.
+func createTestCertificateWithValidity(t *testing.T, notBefore, notAfter time.Time) (certFile, keyFile string, cleanup func()) {
+	t.Helper()
 
 	// Generate a private key
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
@@ -166,8 +633,8 @@ func createTestCertificate(t *testing.T) (certFile, keyFile string, cleanup func
 			Organization: []string{"Test Org"},
 			CommonName:   "test.example.com",
 		},
-		NotBefore:             time.Now(),
-		NotAfter:              time.Now().Add(time.Hour),
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
 		KeyUsage:              x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		BasicConstraintsValid: true,
@@ -203,3 +670,94 @@ func createTestCertificate(t *testing.T) (certFile, keyFile string, cleanup func
 
 	return certF.Name(), keyF.Name(), cleanup
 }
+
+// encryptTestKey rewrites an unencrypted PEM key file as a PBES2 (PBKDF2 +
+// AES-256-CBC) encrypted PKCS#8 key, as produced by "openssl pkcs8 -topk8".
+func encryptTestKey(t *testing.T, keyFile, passphrase string) {
+	t.Helper()
+	encryptTestKeyWithIterations(t, keyFile, passphrase, 2048)
+}
+
+// encryptTestKeyWithIterations is encryptTestKey with the PBKDF2 iteration
+// count recorded in the key parameters overridden. The key is always derived
+// with 2048 iterations, so it only decrypts when iterations is 2048.
+func encryptTestKeyWithIterations(t *testing.T, keyFile, passphrase string, iterations int) {
+	t.Helper()
+
+	keyPEM, err := os.ReadFile(keyFile)
+	require.NoError(t, err)
+	block, _ := pem.Decode(keyPEM)
+	require.NotNil(t, block)
+
+	privateKey, err := x509.ParseECPrivateKey(block.Bytes)
+	require.NoError(t, err)
+	plain, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	_, err = rand.Read(salt)
+	require.NoError(t, err)
+	_, err = rand.Read(iv)
+	require.NoError(t, err)
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, 2048, 32)
+	require.NoError(t, err)
+
+	padLen := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	aesBlock, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(aesBlock, iv).CryptBlocks(encrypted, plain)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	require.NoError(t, err)
+	ivParam, err := asn1.Marshal(iv)
+	require.NoError(t, err)
+	schemeParams, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	require.NoError(t, err)
+	der, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: schemeParams}},
+		EncryptedData: encrypted,
+	})
+	require.NoError(t, err)
+
+	out := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(keyFile, out, 0o600))
+}
+
+// createTestP12 writes a password-protected PKCS#12 bundle to a temp dir.
+func createTestP12(t *testing.T, password string) string {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "p12.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+
+	data, err := pkcs12.Modern.Encode(privateKey, cert, nil, password)
+	require.NoError(t, err)
+
+	p12File := filepath.Join(t.TempDir(), "client.p12")
+	require.NoError(t, os.WriteFile(p12File, data, 0o600))
+	return p12File
+}
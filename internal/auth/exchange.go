@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExchangeExpirationDays is the lifetime requested for exchanged tokens.
+const DefaultExchangeExpirationDays = 1
+
+// exchangeRefreshMargin is how long before expiry a cached token is replaced.
+const exchangeRefreshMargin = 5 * time.Minute
+
+// TokenCache persists exchanged tokens between CLI invocations.
+type TokenCache interface {
+	// Load returns the cached token, or nil if there is none.
+	Load() (*TokenResponse, error)
+	// Save stores a newly exchanged token.
+	Save(token *TokenResponse) error
+}
+
+// ExchangeAuth exchanges a client certificate for a short-lived API token
+// via the api_credentials API, then authenticates with that token. The
+// certificate is only read when no valid cached token is available. Every
+// exchange creates an API credential with a unique name; the credential of
+// the replaced token is revoked, so refreshes do not pile up credentials in
+// the tenant. A credential that cannot be revoked expires after
+// ExpirationDays.
+type ExchangeAuth struct {
+	// Cert authenticates the exchange request (CertAuth or P12Auth).
+	Cert Authenticator
+	// APIURL is the tenant API base URL.
+	APIURL string
+	// ExpirationDays is the lifetime requested for new tokens.
+	ExpirationDays int
+	// Cache stores tokens across invocations (optional).
+	Cache TokenCache
+
+	mu    sync.Mutex
+	token *TokenResponse
+}
+
+// NewExchangeAuth creates a new token-exchange authenticator.
+func NewExchangeAuth(cert Authenticator, apiURL string, cache TokenCache) *ExchangeAuth {
+	return &ExchangeAuth{
+		Cert:           cert,
+		APIURL:         strings.TrimSuffix(apiURL, "/"),
+		ExpirationDays: DefaultExchangeExpirationDays,
+		Cache:          cache,
+	}
+}
+
+// GetToken returns a valid session token, exchanging the certificate if the
+// cached token is missing or about to expire.
+func (a *ExchangeAuth) GetToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if tokenValid(a.token) {
+		return a.token.AccessToken, nil
+	}
+
+	previous := a.token
+	if a.Cache != nil {
+		cached, err := a.Cache.Load()
+		if err == nil && tokenValid(cached) {
+			a.token = cached
+			return cached.AccessToken, nil
+		}
+		if err == nil && cached != nil {
+			previous = cached
+		}
+	}
+
+	client, err := a.Cert.GetHTTPClient()
+	if err != nil {
+		return "", err
+	}
+	token, err := a.exchange(client)
+	if err != nil {
+		return "", err
+	}
+
+	if a.Cache != nil {
+		if err := a.Cache.Save(token); err != nil {
+			// A credential that is not cached would never be revoked
+			_ = a.revoke(client, token.CredentialName)
+			return "", fmt.Errorf("failed to cache session token: %w", err)
+		}
+	}
+	a.token = token
+
+	// The new token works either way, so a failed revocation is not an error
+	if previous != nil && previous.CredentialName != "" && previous.CredentialName != token.CredentialName {
+		_ = a.revoke(client, previous.CredentialName)
+	}
+
+	return token.AccessToken, nil
+}
+
+// GetHTTPClient ensures a session token is available and returns a plain
+// HTTP client; requests are authenticated with the token, not the certificate.
+func (a *ExchangeAuth) GetHTTPClient() (*http.Client, error) {
+	if _, err := a.GetToken(); err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+	}, nil
+}
+
+// exchange creates a new API token using the certificate-authenticated client.
+func (a *ExchangeAuth) exchange(client *http.Client) (*TokenResponse, error) {
+	name, err := sessionCredentialName()
+	if err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		Name                string    `json:"name"`
+		Data                string    `json:"data"`
+		ExpirationTimestamp time.Time `json:"expiration_timestamp"`
+	}
+	err = a.post(client, "/api/web/namespaces/system/api_credentials", map[string]interface{}{
+		"name":            name,
+		"namespace":       "system",
+		"expiration_days": a.ExpirationDays,
+		"spec": map[string]interface{}{
+			"type": "API_TOKEN",
+		},
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	if created.Data == "" {
+		return nil, fmt.Errorf("token exchange response did not contain a token")
+	}
+
+	token := &TokenResponse{
+		AccessToken:    created.Data,
+		TokenType:      "APIToken",
+		ExpiresAt:      created.ExpirationTimestamp,
+		CredentialName: name,
+	}
+	if created.Name != "" {
+		token.CredentialName = created.Name
+	}
+	if token.ExpiresAt.IsZero() {
+		token.ExpiresAt = time.Now().Add(time.Duration(a.ExpirationDays) * 24 * time.Hour)
+	}
+
+	return token, nil
+}
+
+// revoke revokes the API credential of a replaced token.
+func (a *ExchangeAuth) revoke(client *http.Client, name string) error {
+	err := a.post(client, "/api/web/namespaces/system/revoke/api_credentials", map[string]interface{}{
+		"name":      name,
+		"namespace": "system",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API credential %q: %w", name, err)
+	}
+	return nil
+}
+
+// post sends a JSON request to the tenant API and decodes the response into
+// out, unless out is nil.
+func (a *ExchangeAuth) post(client *http.Client, path string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.APIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req) //nolint:gosec // G107: URL is constructed from known config
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// sessionCredentialName returns a unique name for the API credential of an
+// exchanged token.
+func sessionCredentialName() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate credential name: %w", err)
+	}
+	return fmt.Sprintf("f5xcctl-session-%d-%s", time.Now().Unix(), hex.EncodeToString(suffix)), nil
+}
+
+// tokenValid reports whether token is usable for at least the refresh margin.
+func tokenValid(token *TokenResponse) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	return time.Until(token.ExpiresAt) > exchangeRefreshMargin
+}
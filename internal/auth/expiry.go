@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultExpiryWarningWindow is how long before expiry a client certificate
+// starts producing warnings.
+const DefaultExpiryWarningWindow = 30 * 24 * time.Hour
+
+// CertInfo summarizes the identity and validity of a client certificate.
+type CertInfo struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+}
+
+// NewCertInfo builds a CertInfo from a parsed certificate.
+func NewCertInfo(leaf *x509.Certificate) *CertInfo {
+	return &CertInfo{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: formatSerial(leaf.SerialNumber.Bytes()),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+	}
+}
+
+// ExpiresIn returns the time remaining until the certificate expires.
+// The result is negative once the certificate has expired.
+func (i *CertInfo) ExpiresIn() time.Duration {
+	return time.Until(i.NotAfter)
+}
+
+// IsExpired returns true if the certificate is past its NotAfter date.
+func (i *CertInfo) IsExpired() bool {
+	return time.Now().After(i.NotAfter)
+}
+
+// IsNotYetValid returns true if the certificate's NotBefore date is in the future.
+func (i *CertInfo) IsNotYetValid() bool {
+	return time.Now().Before(i.NotBefore)
+}
+
+// ReadPEMCertificate reads the first certificate from a PEM file.
+func ReadPEMCertificate(certFile string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found in %s", certFile)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		return leaf, nil
+	}
+}
+
+// CheckCertificateExpiry validates the leaf certificate's validity period.
+// It returns an error if the certificate has expired or is not yet valid, and
+// writes a warning to w if it expires within window. A nil w means os.Stderr.
+func CheckCertificateExpiry(cert *tls.Certificate, window time.Duration, w io.Writer) error {
+	leaf, err := leafCertificate(cert)
+	if err != nil {
+		return err
+	}
+
+	info := NewCertInfo(leaf)
+	switch {
+	case info.IsExpired():
+		return fmt.Errorf("client certificate %q expired on %s", info.Subject, info.NotAfter.Format(time.RFC3339))
+	case info.IsNotYetValid():
+		return fmt.Errorf("client certificate %q is not valid until %s", info.Subject, info.NotBefore.Format(time.RFC3339))
+	}
+
+	if remaining := info.ExpiresIn(); remaining <= window {
+		if w == nil {
+			w = os.Stderr
+		}
+		fmt.Fprintf(w, "Warning: client certificate %q expires in %s (%s)\n",
+			info.Subject, formatRemaining(remaining), info.NotAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// leafCertificate returns the parsed leaf of a TLS certificate chain.
+func leafCertificate(cert *tls.Certificate) (*x509.Certificate, error) {
+	if cert.Leaf != nil {
+		return cert.Leaf, nil
+	}
+	if len(cert.Certificate) == 0 {
+		return nil, fmt.Errorf("certificate chain is empty")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return leaf, nil
+}
+
+// formatSerial formats a serial number as colon-separated hex, like openssl.
+func formatSerial(b []byte) string {
+	if len(b) == 0 {
+		return "00"
+	}
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(parts, ":")
+}
+
+// formatRemaining renders a duration in days, or hours when under a day.
+func formatRemaining(d time.Duration) string {
+	if d < 24*time.Hour {
+		hours := int(d.Hours())
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
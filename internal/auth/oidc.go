@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token exchange (RFC 8693) grant and token type identifiers.
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeJWT           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// OIDCFederationAuth implements workload identity federation: it reads an
+// OIDC JWT issued by the CI provider (GitHub Actions, GitLab CI, ...) and
+// exchanges it for an F5XC API token at a token-exchange endpoint.
+type OIDCFederationAuth struct {
+	// TokenFile is a file containing the CI-issued JWT.
+	TokenFile string
+	// TokenEnv is the name of an environment variable containing the JWT.
+	// It is consulted when TokenFile is empty.
+	TokenEnv string
+	// ExchangeURL is the token-exchange endpoint.
+	ExchangeURL string
+	// Audience is passed to the exchange endpoint (optional).
+	Audience string
+
+	mu    sync.Mutex
+	token *TokenResponse
+}
+
+// NewOIDCFederationAuth creates a new OIDC federation authenticator.
+func NewOIDCFederationAuth(tokenFile, tokenEnv, exchangeURL, audience string) *OIDCFederationAuth {
+	return &OIDCFederationAuth{
+		TokenFile:   tokenFile,
+		TokenEnv:    tokenEnv,
+		ExchangeURL: exchangeURL,
+		Audience:    audience,
+	}
+}
+
+// GetToken returns an F5XC token, exchanging the CI JWT when needed.
+func (a *OIDCFederationAuth) GetToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if tokenValid(a.token) {
+		return a.token.AccessToken, nil
+	}
+
+	token, err := a.exchange()
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+
+	return token.AccessToken, nil
+}
+
+// GetHTTPClient performs the initial exchange and returns a plain HTTP client.
+func (a *OIDCFederationAuth) GetHTTPClient() (*http.Client, error) {
+	if _, err := a.GetToken(); err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+	}, nil
+}
+
+// readJWT reads the CI-provided identity token. The file is re-read on every
+// exchange since CI runners may rotate it during long jobs.
+func (a *OIDCFederationAuth) readJWT() (string, error) {
+	switch {
+	case a.TokenFile != "":
+		data, err := os.ReadFile(a.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read OIDC token file: %w", err)
+		}
+		jwt := strings.TrimSpace(string(data))
+		if jwt == "" {
+			return "", fmt.Errorf("OIDC token file %s is empty", a.TokenFile)
+		}
+		return jwt, nil
+	case a.TokenEnv != "":
+		jwt := strings.TrimSpace(os.Getenv(a.TokenEnv))
+		if jwt == "" {
+			return "", fmt.Errorf("OIDC token environment variable %s is not set", a.TokenEnv)
+		}
+		return jwt, nil
+	default:
+		return "", fmt.Errorf("no OIDC token source configured: set oidc-token-file or oidc-token-env")
+	}
+}
+
+// exchange trades the CI JWT for an F5XC token.
+func (a *OIDCFederationAuth) exchange() (*TokenResponse, error) {
+	if a.ExchangeURL == "" {
+		return nil, fmt.Errorf("no OIDC token exchange URL configured: set oidc-exchange-url")
+	}
+
+	jwt, err := a.readJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", grantTypeTokenExchange)
+	data.Set("subject_token", jwt)
+	data.Set("subject_token_type", tokenTypeJWT)
+	if a.Audience != "" {
+		data.Set("audience", a.Audience)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.ExchangeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G107: URL is constructed from known config
+	if err != nil {
+		return nil, fmt.Errorf("OIDC token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("OIDC token exchange failed with status %d: %s %s",
+				resp.StatusCode, errResp.Error, errResp.ErrorDescription)
+		}
+		return nil, fmt.Errorf("OIDC token exchange failed with status %d", resp.StatusCode)
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token exchange response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token exchange response did not contain an access token")
+	}
+
+	// Set expiration time; assume a short lifetime if the server omits it
+	if token.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	} else {
+		token.ExpiresAt = time.Now().Add(time.Hour)
+	}
+
+	return &token, nil
+}
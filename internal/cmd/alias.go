@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/f5/f5xcctl/internal/config"
+)
+
+// aliasAnnotation marks commands registered from the aliases section of the
+// configuration; its value is the command line the alias expands to.
+const aliasAnnotation = "f5xcctl/alias"
+
+// commandConfig is the configuration aliases and default flags are read
+// from. It is loaded before flags are parsed.
+var commandConfig *config.Config
+
+// loadCommandConfig loads the configuration named by --config in args, or the
+// default one. Aliases are optional, so a missing or broken configuration
+// yields nil and is reported later by the command itself.
+func loadCommandConfig(args []string) *config.Config {
+	path := ""
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			path = value
+		} else if arg == "--config" && i+1 < len(args) {
+			path = args[i+1]
+		}
+	}
+
+	cfg, err := config.Load(path, "")
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+// registerAliases adds a command for every alias so that aliases are listed
+// in help and shell completion. Aliases never shadow built-in commands.
+func registerAliases(cfg *config.Config) {
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isBuiltinCommand(name) {
+			continue
+		}
+
+		line := cfg.Aliases[name]
+		rootCmd.AddCommand(&cobra.Command{
+			Use:                name,
+			Short:              "Alias for: " + line,
+			Annotations:        map[string]string{aliasAnnotation: line},
+			DisableFlagParsing: true,
+			// Aliases are expanded before execution, see expandArgs
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("alias %q was not expanded", name)
+			},
+		})
+		commandTree[""] = append(commandTree[""], prompt.Suggest{Text: name, Description: "Alias for: " + line})
+	}
+}
+
+// isBuiltinCommand reports whether name is a top-level command or one of its
+// aliases.
+func isBuiltinCommand(name string) bool {
+	for _, cmd := range rootCmd.Commands() {
+		if _, ok := cmd.Annotations[aliasAnnotation]; ok {
+			continue
+		}
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandArgs expands an alias at the start of the command line and adds the
+// default flags configured for the resulting command. Shell completion
+// requests are left alone so that aliases complete as commands.
+func expandArgs(cfg *config.Config, args []string) ([]string, error) {
+	if cfg == nil || len(args) == 0 || strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		return args, nil
+	}
+
+	target, rest, err := rootCmd.Find(args)
+	if err != nil {
+		return args, nil
+	}
+
+	if line, ok := target.Annotations[aliasAnnotation]; ok {
+		words, err := config.SplitCommandLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %q: %w", target.Name(), err)
+		}
+		aliased, _, err := rootCmd.Find(words)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %q: %w", target.Name(), err)
+		}
+
+		positional, flagArgs := splitArgs(commandFlags(aliased), rest)
+		expanded, err := cfg.ExpandAlias(target.Name(), positional)
+		if err != nil {
+			return nil, err
+		}
+		for _, words := range flagArgs {
+			expanded = append(expanded, words...)
+		}
+
+		args = expanded
+		if target, _, err = rootCmd.Find(args); err != nil {
+			return args, nil
+		}
+	}
+
+	return addDefaultFlags(cfg, target, args)
+}
+
+// addDefaultFlags adds the default flags configured for target that args do
+// not set already.
+func addDefaultFlags(cfg *config.Config, target *cobra.Command, args []string) ([]string, error) {
+	if target == rootCmd {
+		return args, nil
+	}
+
+	path := strings.TrimPrefix(target.CommandPath(), rootCmd.Name()+" ")
+	defaults, err := cfg.DefaultFlags(path)
+	if err != nil || len(defaults) == 0 {
+		return args, err
+	}
+
+	flags := commandFlags(target)
+	_, given := splitArgs(flags, args)
+	set := make(map[string]bool, len(given))
+	for _, words := range given {
+		set[flagName(flags, words[0])] = true
+	}
+
+	var extra []string
+	_, defaultFlags := splitArgs(flags, defaults)
+	for _, words := range defaultFlags {
+		if !set[flagName(flags, words[0])] {
+			extra = append(extra, words...)
+		}
+	}
+
+	// Flags must come before a "--" separator
+	end := len(args)
+	for i, arg := range args {
+		if arg == "--" {
+			end = i
+			break
+		}
+	}
+	result := make([]string, 0, len(args)+len(extra))
+	result = append(result, args[:end]...)
+	result = append(result, extra...)
+	return append(result, args[end:]...), nil
+}
+
+// commandFlags returns the local and inherited flags of cmd.
+func commandFlags(cmd *cobra.Command) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(cmd.Flags())
+	flags.AddFlagSet(cmd.InheritedFlags())
+	return flags
+}
+
+// splitArgs separates positional arguments from flags, using flags to tell
+// which flags take a value. Each flag is returned with its value, if any.
+// Unknown flags are assumed not to take a value.
+func splitArgs(flags *pflag.FlagSet, args []string) (positional []string, flagArgs [][]string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(positional, args[i+1:]...), flagArgs
+		case strings.HasPrefix(arg, "--"):
+			words := []string{arg}
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			if f := flags.Lookup(name); f != nil && !hasValue && f.NoOptDefVal == "" && i+1 < len(args) {
+				i++
+				words = append(words, args[i])
+			}
+			flagArgs = append(flagArgs, words)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Shorthands may be combined (-Aw) and take a value inline (-nprod)
+			words := []string{arg}
+			for j := 1; j < len(arg); j++ {
+				f := flags.ShorthandLookup(arg[j : j+1])
+				if f == nil || f.NoOptDefVal != "" {
+					continue
+				}
+				if j == len(arg)-1 && i+1 < len(args) {
+					i++
+					words = append(words, args[i])
+				}
+				break
+			}
+			flagArgs = append(flagArgs, words)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	return positional, flagArgs
+}
+
+// flagName returns the long name of the flag in arg.
+func flagName(flags *pflag.FlagSet, arg string) string {
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		name, _, _ = strings.Cut(name, "=")
+		return name
+	}
+	if len(arg) > 1 {
+		if f := flags.ShorthandLookup(arg[1:2]); f != nil {
+			return f.Name
+		}
+	}
+	return arg
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"crypto/x509"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/f5/f5xcctl/internal/auth"
 	"github.com/f5/f5xcctl/internal/config"
+	"github.com/f5/f5xcctl/internal/output"
 )
 
 var authCmd = &cobra.Command{
@@ -48,6 +52,24 @@ var authStatusCmd = &cobra.Command{
 	RunE:  runAuthStatus,
 }
 
+var authCertInfoCmd = &cobra.Command{
+	Use:   "cert-info",
+	Short: "Show details of the configured client certificate",
+	Long: `Display the subject, issuer, serial number and validity period of the
+client certificate used by the current profile (P12 or PEM).
+
+The F5XC_API_P12_FILE and F5XC_CERT_FILE environment variables take
+precedence over the profile, matching how API clients are created.
+
+Examples:
+  # Show certificate details for the current profile
+  f5xcctl auth cert-info
+
+  # Show certificate details for another profile
+  f5xcctl auth cert-info --profile production`,
+	RunE: runAuthCertInfo,
+}
+
 var (
 	authAPIToken string
 )
@@ -58,10 +80,11 @@ func init() {
 	authCmd.AddCommand(authLoginCmd)
 	authCmd.AddCommand(authLogoutCmd)
 	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authCertInfoCmd)
 }
 
 func runAuthLogin(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load("", profile)
+	cfg, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("please run 'f5xcctl configure' first: %w", err)
 	}
@@ -73,14 +96,12 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 
 	if authAPIToken != "" {
 		// Non-interactive: use provided token
-		creds, err := config.LoadCredentials()
+		creds, err := loadOrNewCredentials(cfg)
 		if err != nil {
-			creds = &config.Credentials{
-				Profiles: make(map[string]config.ProfileCredentials),
-			}
+			return err
 		}
 
-		creds.Profiles[cfg.CurrentProfile] = config.ProfileCredentials{
+		creds.Profiles[cfg.CurrentUser()] = config.ProfileCredentials{
 			APIToken: authAPIToken,
 		}
 
@@ -88,7 +109,7 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to save credentials: %w", err)
 		}
 
-		fmt.Println("✓ API token saved successfully")
+		output.Successf("API token saved successfully")
 		return nil
 	}
 
@@ -102,14 +123,12 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save the token
-	creds, err := config.LoadCredentials()
+	creds, err := loadOrNewCredentials(cfg)
 	if err != nil {
-		creds = &config.Credentials{
-			Profiles: make(map[string]config.ProfileCredentials),
-		}
+		return err
 	}
 
-	creds.Profiles[cfg.CurrentProfile] = config.ProfileCredentials{
+	creds.Profiles[cfg.CurrentUser()] = config.ProfileCredentials{
 		APIToken:  token.AccessToken,
 		ExpiresAt: token.ExpiresAt,
 	}
@@ -118,40 +137,40 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	fmt.Println("✓ Successfully authenticated")
+	output.Successf("Successfully authenticated")
 	return nil
 }
 
 func runAuthLogout(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load("", profile)
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	creds, err := config.LoadCredentials()
+	creds, err := loadCredentials(cfg)
 	if err != nil {
 		return fmt.Errorf("no credentials found")
 	}
 
-	delete(creds.Profiles, cfg.CurrentProfile)
+	delete(creds.Profiles, cfg.CurrentUser())
 
 	if err := config.SaveCredentials(creds); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	fmt.Println("✓ Logged out successfully")
+	output.Successf("Logged out successfully")
 	return nil
 }
 
 func runAuthStatus(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load("", profile)
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Println("Status: Not configured")
 		fmt.Println("\nRun 'f5xcctl configure' to set up the CLI")
 		return nil //nolint:nilerr // intentionally return nil to show friendly status
 	}
 
-	creds, err := config.LoadCredentials()
+	creds, err := loadCredentials(cfg)
 	if err != nil {
 		fmt.Printf("Profile: %s\n", cfg.CurrentProfile)
 		fmt.Println("Status:  Not authenticated")
@@ -159,22 +178,131 @@ func runAuthStatus(cmd *cobra.Command, args []string) error {
 		return nil //nolint:nilerr // intentionally return nil to show friendly status
 	}
 
-	profileCreds, ok := creds.Profiles[cfg.CurrentProfile]
-	if !ok || profileCreds.APIToken == "" {
+	profileCreds, ok := creds.Profiles[cfg.CurrentUser()]
+	if !ok || (profileCreds.APIToken == "" && profileCreds.SessionToken == "") {
 		fmt.Printf("Profile: %s\n", cfg.CurrentProfile)
 		fmt.Println("Status:  Not authenticated")
 		fmt.Println("\nRun 'f5xcctl auth login' to authenticate")
 		return nil
 	}
 
+	// Only tenants the credentials can act in are reported
+	if asTenant != "" {
+		if _, err := getClient(); err != nil {
+			return err
+		}
+	}
+
 	currentProfile := cfg.GetCurrentProfile()
 	fmt.Printf("Profile: %s\n", cfg.CurrentProfile)
 	fmt.Printf("Tenant:  %s\n", currentProfile.Tenant)
+	if asTenant != "" {
+		fmt.Printf("Acting:  %s (delegated via %s)\n", asTenant, currentProfile.Tenant)
+	}
 	fmt.Println("Status:  Authenticated")
 
 	if !profileCreds.ExpiresAt.IsZero() {
 		fmt.Printf("Expires: %s\n", profileCreds.ExpiresAt.Format("2006-01-02 15:04:05"))
 	}
+	if profileCreds.SessionToken != "" {
+		fmt.Printf("Session: exchanged token, expires %s\n", profileCreds.SessionExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
+
+func runAuthCertInfo(cmd *cobra.Command, args []string) error {
+	leaf, source, err := loadConfiguredCertificate()
+	if err != nil {
+		return err
+	}
+
+	info := auth.NewCertInfo(leaf)
+
+	status := "Valid"
+	switch {
+	case info.IsExpired():
+		status = "Expired"
+	case info.IsNotYetValid():
+		status = "Not yet valid"
+	case info.ExpiresIn() <= auth.DefaultExpiryWarningWindow:
+		status = "Expiring soon"
+	}
+
+	fmt.Printf("Certificate: %s\n", source)
+	fmt.Printf("Subject:     %s\n", info.Subject)
+	fmt.Printf("Issuer:      %s\n", info.Issuer)
+	fmt.Printf("Serial:      %s\n", info.SerialNumber)
+	fmt.Printf("Not Before:  %s\n", info.NotBefore.Format(time.RFC3339))
+	fmt.Printf("Not After:   %s\n", info.NotAfter.Format(time.RFC3339))
+	if info.IsExpired() {
+		fmt.Printf("Status:      %s (%d days ago)\n", status, int(-info.ExpiresIn().Hours()/24))
+	} else {
+		fmt.Printf("Status:      %s (%d days remaining)\n", status, int(info.ExpiresIn().Hours()/24))
+	}
 
 	return nil
 }
+
+// loadConfiguredCertificate loads the client certificate from the environment
+// or the current profile and returns it along with the file it came from.
+func loadConfiguredCertificate() (*x509.Certificate, string, error) {
+	if p12File := os.Getenv("F5XC_API_P12_FILE"); p12File != "" {
+		password, err := auth.ReadSecretEnv("F5XC_P12_PASSWORD")
+		if err != nil {
+			return nil, "", err
+		}
+		leaf, err := loadP12Leaf(p12File, password)
+		return leaf, p12File, err
+	}
+	if certFile := os.Getenv("F5XC_CERT_FILE"); certFile != "" {
+		leaf, err := auth.ReadPEMCertificate(certFile)
+		return leaf, certFile, err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	currentProfile := cfg.GetCurrentProfile()
+	if currentProfile == nil {
+		return nil, "", fmt.Errorf("no profile configured")
+	}
+
+	switch currentProfile.AuthMethod {
+	case "p12":
+		if currentProfile.P12File == "" {
+			return nil, "", fmt.Errorf("no P12 file configured for profile %q", cfg.CurrentProfile)
+		}
+		var password string
+		if creds, err := loadCredentials(cfg); err == nil {
+			password = creds.Profiles[cfg.CurrentUser()].P12Password
+		}
+		if password == "" {
+			if password, err = auth.ReadSecretEnv("F5XC_P12_PASSWORD"); err != nil {
+				return nil, "", err
+			}
+		}
+		leaf, err := loadP12Leaf(currentProfile.P12File, password)
+		return leaf, currentProfile.P12File, err
+	case "certificate":
+		if currentProfile.CertFile == "" {
+			return nil, "", fmt.Errorf("no certificate file configured for profile %q", cfg.CurrentProfile)
+		}
+		leaf, err := auth.ReadPEMCertificate(currentProfile.CertFile)
+		return leaf, currentProfile.CertFile, err
+	default:
+		return nil, "", fmt.Errorf("profile %q does not use certificate authentication (auth-method: p12 or certificate)", cfg.CurrentProfile)
+	}
+}
+
+func loadP12Leaf(p12File, password string) (*x509.Certificate, error) {
+	p12Auth := auth.NewP12Auth(p12File, password)
+	p12Auth.PasswordPrompt = passwordPrompt()
+	cert, err := p12Auth.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	return cert.Leaf, nil
+}
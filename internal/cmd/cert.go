@@ -120,7 +120,7 @@ func runCertList(cmd *cobra.Command, args []string) error {
 		return output.Print(outputFmt, list.Items)
 	}
 
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No certificates found in namespace %q", ns)
 		return nil
 	}
@@ -144,7 +144,7 @@ func runCertList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newCertGetCmd() *cobra.Command {
@@ -2,11 +2,27 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/f5/f5xcctl/internal/config"
+	"github.com/f5/f5xcctl/internal/output"
+	"github.com/f5/f5xcctl/internal/runtime"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -34,6 +50,7 @@ func TestRootCommand(t *testing.T) {
 		"cert",
 		"dns",
 		"monitor",
+		"tenant",
 	}
 
 	for _, expected := range expectedCommands {
@@ -227,6 +244,80 @@ func TestGetAPIURL(t *testing.T) {
 	apiURL = ""
 }
 
+func TestDeriveTenantURL(t *testing.T) {
+	tests := []struct {
+		parent string
+		name   string
+		want   string
+	}{
+		{"https://parent.console.ves.volterra.io", "child-a", "https://child-a.console.ves.volterra.io"},
+		{"https://parent.staging.volterra.us/", "child-b", "https://child-b.staging.volterra.us"},
+		{"", "child-c", "https://child-c.console.ves.volterra.io"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, deriveTenantURL(tt.parent, tt.name))
+	}
+
+	// Console links are only followed within the parent's domain
+	links := []struct {
+		href string
+		want string
+	}{
+		{"https://child-a-xyz.console.ves.volterra.io/web/home", "https://child-a-xyz.console.ves.volterra.io"},
+		{"https://child-a.attacker.example.com/web/home", "https://child-a.console.ves.volterra.io"},
+		{"https://x.child-a.console.ves.volterra.io/web", "https://child-a.console.ves.volterra.io"},
+		{"http://child-a-xyz.console.ves.volterra.io/web", "https://child-a.console.ves.volterra.io"},
+		{"", "https://child-a.console.ves.volterra.io"},
+	}
+	for _, tt := range links {
+		info := TenantAccessInfo{Name: "child-a"}
+		info.Link.Href = tt.href
+		assert.Equal(t, tt.want, tenantBaseURL(info, "https://parent.console.ves.volterra.io"), tt.href)
+	}
+}
+
+func TestDelegatedClient(t *testing.T) {
+	childStatus := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case managedTenantsPath:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_config": []map[string]interface{}{
+					{"name": "managed-a", "link": map[string]string{"href": "https://managed-a.example.com/web"}},
+				},
+			})
+		case childTenantsPath:
+			w.WriteHeader(childStatus)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "parent-token")
+
+	client, err := runtime.NewClientFromEnv()
+	require.NoError(t, err)
+
+	// The link is outside the parent's domain, so the URL is derived
+	delegated, err := delegatedClient(client, "managed-a")
+	require.NoError(t, err)
+	assert.Equal(t, deriveTenantURL(server.URL, "managed-a"), delegated.BaseURL())
+	assert.Equal(t, server.URL, client.BaseURL())
+
+	_, err = delegatedClient(client, "unknown")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "f5xcctl tenant list")
+
+	// Only a 403 means there are no child tenants
+	childStatus = http.StatusInternalServerError
+	_, err = delegatedClient(client, "unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve tenant")
+}
+
 func TestIsDebug(t *testing.T) {
 	debug = true
 	assert.True(t, IsDebug())
@@ -656,8 +747,13 @@ func TestCommandTreeStructure(t *testing.T) {
 			"monitor",
 		},
 		"namespace": {"list", "get <name>", "create <name>", "delete <name>"},
-		"config":    {"get <key>", "set <key> <value>", "list"},
-		"auth":      {"login", "logout", "status"},
+		"config": {
+			"get <key>", "set <key> <value>", "unset <key>", "list", "view",
+			"set-context <name>", "use-context <name>",
+			"rename-context <old-name> <new-name>", "delete-context <name>",
+			"migrate", "export <profile>", "import <file>",
+		},
+		"auth": {"login", "logout", "status"},
 	}
 
 	// Verify root level commands
@@ -679,9 +775,1013 @@ func TestCommandTreeStructure(t *testing.T) {
 	for _, expected := range expectedTree["namespace"] {
 		assert.True(t, nsSubs[expected], "Namespace command should have %q subcommand", expected)
 	}
+
+	// Verify config subcommands
+	configSubs := make(map[string]bool)
+	for _, cmd := range configCmd.Commands() {
+		configSubs[cmd.Use] = true
+	}
+
+	for _, expected := range expectedTree["config"] {
+		assert.True(t, configSubs[expected], "Config command should have %q subcommand", expected)
+	}
 }
 
 // TestTotalCommandCount verifies expected number of commands.
+func TestExpandArgs(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]string{
+			"lbs":     "get httplb -A -L env,team --sort-by .metadata.name",
+			"lbd":     "describe httplb $1",
+			"version": "get httplb",
+		},
+		Defaults: map[string]string{
+			"get":         "--sort-by .metadata.name -o wide",
+			"config view": "--show-origin",
+		},
+	}
+	suggestions := commandTree[""]
+	registerAliases(cfg)
+	t.Cleanup(func() {
+		commandTree[""] = suggestions
+		for _, cmd := range rootCmd.Commands() {
+			if _, ok := cmd.Annotations[aliasAnnotation]; ok {
+				rootCmd.RemoveCommand(cmd)
+			}
+		}
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "alias with defaults already set",
+			args: []string{"lbs"},
+			want: []string{"get", "httplb", "-A", "-L", "env,team", "--sort-by", ".metadata.name", "-o", "wide"},
+		},
+		{
+			name: "alias with positional argument and flags",
+			args: []string{"--profile", "prod", "lbd", "-n", "shop", "my-lb"},
+			want: []string{"describe", "httplb", "my-lb", "--profile", "prod", "-n", "shop"},
+		},
+		{
+			name: "defaults do not override flags",
+			args: []string{"get", "httplb", "-o", "json"},
+			want: []string{"get", "httplb", "-o", "json", "--sort-by", ".metadata.name"},
+		},
+		{
+			name: "defaults go before the separator",
+			args: []string{"config", "view", "--"},
+			want: []string{"config", "view", "--show-origin", "--"},
+		},
+		{
+			name: "built-in commands win over aliases",
+			args: []string{"version"},
+			want: []string{"version"},
+		},
+		{
+			name: "completion requests are not expanded",
+			args: []string{cobra.ShellCompRequestCmd, "lbs", ""},
+			want: []string{cobra.ShellCompRequestCmd, "lbs", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandArgs(cfg, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	lbs, _, err := rootCmd.Find([]string{"lbs"})
+	require.NoError(t, err)
+	assert.Equal(t, "Alias for: "+cfg.Aliases["lbs"], lbs.Short)
+}
+
+func TestResetCommandState(t *testing.T) {
+	t.Cleanup(resetCommandState)
+
+	// Flags of one command in the interactive shell do not carry over
+	watchOnly = true
+	watchInterval = time.Minute
+	outputWatchEvents = true
+	exportManifests = true
+	rawOutput = true
+	colorMode = output.ColorNever
+	showEvents = false
+	resetCommandState()
+
+	assert.False(t, watchOnly)
+	assert.Equal(t, defaultWatchInterval, watchInterval)
+	assert.False(t, outputWatchEvents)
+	assert.False(t, exportManifests)
+	assert.False(t, rawOutput)
+	assert.Equal(t, output.ColorAuto, colorMode)
+	assert.True(t, showEvents)
+}
+
+func TestConfigureNonInteractive(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if gotAuth != "APIToken secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	oldCfgFile := cfgFile
+	cfgFile = filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+	t.Setenv("F5XC_CREDENTIALS", "")
+
+	// From a file
+	path := filepath.Join(dir, "profile.yaml")
+	content := "tenant: acme\napi-url: " + server.URL + "\napi-token: secret\ndefault-namespace: shop\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	input, err := configureFromFile(path)
+	require.NoError(t, err)
+	require.NoError(t, input.complete())
+	assert.Equal(t, "api-token", input.settings["auth-method"])
+
+	cfg, creds, err := input.build("ci")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", cfg.CurrentProfile)
+	assert.Equal(t, "shop", cfg.GetCurrentProfile().DefaultNamespace)
+	require.NoError(t, testConnectivity(cfg, creds))
+	assert.Equal(t, "APIToken secret", gotAuth)
+
+	creds.Profiles[cfg.CurrentUser()] = config.ProfileCredentials{APIToken: "wrong"}
+	require.Error(t, testConnectivity(cfg, creds))
+
+	// Relative paths are relative to the file, unknown keys are rejected
+	require.NoError(t, os.WriteFile(path, []byte("tenant: acme\np12-file: certs/acme.p12\n"), 0o600))
+	input, err = configureFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "certs", "acme.p12"), input.settings["p12-file"])
+	assert.Equal(t, "p12", input.impliedAuthMethod())
+
+	require.NoError(t, os.WriteFile(path, []byte("tenant: acme\napi_token: x\n"), 0o600))
+	_, err = configureFromFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown key")
+
+	// From the environment
+	t.Setenv("F5XC_TENANT", "acme")
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "secret")
+	t.Setenv("F5XC_NAMESPACE", "ops")
+	input, err = configureFromEnv()
+	require.NoError(t, err)
+	require.NoError(t, input.complete())
+	assert.Equal(t, "acme", input.settings["tenant"])
+	assert.Equal(t, "ops", input.settings["default-namespace"])
+	assert.Equal(t, "api-token", input.settings["auth-method"])
+	assert.Equal(t, "secret", input.creds.APIToken)
+
+	// A credentials file that cannot be read is not replaced
+	corrupt := []byte("profiles: [not: a map\n")
+	require.NoError(t, os.WriteFile(cfg.CredentialsPath(), corrupt, 0o600))
+	_, _, err = input.build("ci")
+	require.Error(t, err)
+	data, err := os.ReadFile(cfg.CredentialsPath())
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, data)
+
+	// Missing credentials are reported before anything is saved
+	input = &configureInput{settings: map[string]string{"tenant": "acme", "auth-method": "certificate"}}
+	require.Error(t, input.complete())
+}
+
+func TestConfigSetContextKeepsUnreadableConfig(t *testing.T) {
+	dir := t.TempDir()
+	oldCfgFile := cfgFile
+	cfgFile = filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+
+	corrupt := []byte("tenants: [not: a map\n")
+	require.NoError(t, os.WriteFile(cfgFile, corrupt, 0o600))
+
+	require.Error(t, runConfigSetContext(configSetContextCmd, []string{"dev"}))
+	data, err := os.ReadFile(cfgFile)
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, data)
+}
+
+func TestConfigSetContextInvalidAPIURL(t *testing.T) {
+	dir := t.TempDir()
+	oldCfgFile := cfgFile
+	cfgFile = filepath.Join(dir, "config.yaml")
+	oldTenant, oldAPIURL := setContextTenant, setContextAPIURL
+	t.Cleanup(func() {
+		cfgFile = oldCfgFile
+		setContextTenant, setContextAPIURL = oldTenant, oldAPIURL
+	})
+
+	setContextTenant, setContextAPIURL = "acme", "acme.console.ves.volterra.io"
+	err := runConfigSetContext(configSetContextCmd, []string{"dev"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an http(s) URL")
+	_, err = os.Stat(cfgFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfigProfilesRenameUnreadableCredentials(t *testing.T) {
+	dir := t.TempDir()
+	oldCfgFile := cfgFile
+	cfgFile = filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+	t.Setenv("F5XC_CREDENTIALS", "")
+
+	cfg := &config.Config{}
+	cfg.SetPath(cfgFile)
+	require.NoError(t, cfg.CreateProfile("dev"))
+	require.NoError(t, cfg.SetProfileKey("dev", "tenant", "acme"))
+	require.NoError(t, config.Save(cfg))
+
+	corrupt := []byte("profiles: [not: a map\n")
+	require.NoError(t, os.WriteFile(cfg.CredentialsPath(), corrupt, 0o600))
+
+	err := configProfilesRenameCmd.RunE(configProfilesRenameCmd, []string{"dev", "stage"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to update credentials")
+	data, err := os.ReadFile(cfg.CredentialsPath())
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, data)
+}
+
+func TestGuardProfile(t *testing.T) {
+	t.Setenv("F5XC_CONFIRM_TENANT", "")
+
+	find := func(args ...string) *cobra.Command {
+		cmd, _, err := rootCmd.Find(args)
+		require.NoError(t, err)
+		return cmd
+	}
+
+	readOnly := &config.Profile{Tenant: "prod-tenant", ReadOnly: true}
+	protected := &config.Profile{Tenant: "prod-tenant", Protected: true}
+
+	tests := []struct {
+		name    string
+		cmd     *cobra.Command
+		profile *config.Profile
+		confirm string
+		wantErr string
+	}{
+		{name: "unprotected profile", cmd: find("delete"), profile: &config.Profile{Tenant: "dev"}},
+		{name: "no profile", cmd: find("apply")},
+		{name: "read-only allows reads", cmd: find("get"), profile: readOnly},
+		{name: "read-only allows config changes", cmd: find("config", "set"), profile: readOnly},
+		{name: "read-only refuses apply", cmd: find("apply"), profile: readOnly, wantErr: "is read-only"},
+		{name: "read-only refuses domain delete", cmd: find("lb", "http", "delete"), profile: readOnly, wantErr: "'lb http delete' is not allowed"},
+		{name: "read-only refuses cert upload", cmd: find("cert", "upload"), profile: readOnly, wantErr: "is read-only"},
+		{name: "protected confirmed", cmd: find("patch"), profile: protected, confirm: "prod-tenant"},
+		{name: "protected wrong confirmation", cmd: find("label"), profile: protected, confirm: "dev", wantErr: "does not match tenant"},
+		{name: "protected without terminal", cmd: find("namespace", "create"), profile: protected, wantErr: "F5XC_CONFIRM_TENANT=prod-tenant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("F5XC_CONFIRM_TENANT", tt.confirm)
+			err := guardProfile(tt.cmd, "prod", tt.profile)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("dry run is allowed", func(t *testing.T) {
+		cmd := find("annotate")
+		require.NoError(t, cmd.Flags().Set("dry-run", "true"))
+		t.Cleanup(func() {
+			_ = cmd.Flags().Set("dry-run", "false")
+			cmd.Flags().Lookup("dry-run").Changed = false
+		})
+		assert.NoError(t, guardProfile(cmd, "prod", readOnly))
+	})
+
+	t.Run("unreadable config fails closed", func(t *testing.T) {
+		oldCfgFile := cfgFile
+		cfgFile = filepath.Join(t.TempDir(), "config.yaml")
+		t.Cleanup(func() { cfgFile = oldCfgFile })
+		require.NoError(t, os.WriteFile(cfgFile, []byte("contexts: [not: a map\n"), 0o600))
+
+		err := rootCmd.PersistentPreRunE(find("delete"), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load configuration")
+		assert.NoError(t, rootCmd.PersistentPreRunE(find("get"), nil))
+	})
+}
+
+func TestColumnValue(t *testing.T) {
+	lb := columnView(map[string]interface{}{
+		"name": "shop",
+		"get_spec": map[string]interface{}{
+			"domains":                         []interface{}{"shop.example.com", "www.shop.example.com"},
+			"advertise_on_public_default_vip": map[string]interface{}{},
+			"app_firewall":                    map[string]interface{}{"name": "shop-waf"},
+			"default_route_pools": []interface{}{
+				map[string]interface{}{"pool": map[string]interface{}{"name": "shop-pool"}},
+			},
+			"port": float64(443),
+		},
+	})
+
+	tests := []struct {
+		name string
+		col  PrinterColumn
+		want string
+	}{
+		{name: "list", col: PrinterColumn{JSONPaths: []string{".spec.domains"}}, want: "shop.example.com,www.shop.example.com"},
+		{name: "one-of marker", col: advertiseColumn, want: "advertise_on_public_default_vip"},
+		{name: "first matching path", col: PrinterColumn{JSONPaths: []string{".spec.app_firewall.name", ".spec.disable_waf"}}, want: "shop-waf"},
+		{name: "wildcard", col: PrinterColumn{JSONPaths: []string{".spec.default_route_pools[*].pool.name"}}, want: "shop-pool"},
+		{name: "number", col: PrinterColumn{JSONPaths: []string{".spec.port"}}, want: "443"},
+		{name: "missing", col: PrinterColumn{JSONPaths: []string{".spec.missing"}}, want: "<none>"},
+		{name: "truncated", col: PrinterColumn{JSONPaths: []string{".spec.domains"}, Width: 12}, want: "shop.exam..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, columnValue(lb, tt.col))
+		})
+	}
+}
+
+func TestPrintResourceTable(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{
+			"name":      "shop",
+			"namespace": "prod",
+			"labels":    map[string]interface{}{"team": "web, payments"},
+			"get_spec": map[string]interface{}{
+				"domains":          []interface{}{"shop.example.com"},
+				"do_not_advertise": map[string]interface{}{},
+				"disable_waf":      map[string]interface{}{},
+				"https_auto_cert":  map[string]interface{}{},
+			},
+		},
+	}
+	rt := ResourceRegistry["http_loadbalancer"]
+
+	render := func(wide bool) []string {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		stdout := os.Stdout
+		os.Stdout = w
+		err = printResourceTable(items, rt, wide)
+		os.Stdout = stdout
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(r)
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(buf.String()), "\n")
+	}
+
+	lines := render(false)
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"NAME", "DOMAINS", "VIP", "WAF", "AGE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"shop", "shop.example.com", "do_not_advertise", "disable_waf", "<unknown>"}, strings.Fields(lines[1]))
+	// Columns are aligned
+	assert.Equal(t, strings.Index(lines[0], "VIP"), strings.Index(lines[1], "do_not_advertise"))
+
+	lines = render(true)
+	assert.Equal(t, []string{"NAME", "NAMESPACE", "DOMAINS", "VIP", "WAF", "TYPE", "POOLS", "UID", "CREATED"}, strings.Fields(lines[0]))
+	assert.Contains(t, lines[1], "https_auto_cert")
+
+	// CSV, TSV and Markdown keep the printer and label columns
+	t.Cleanup(func() { outputFmt, labelColumns = "table", nil })
+	labelColumns = []string{"team"}
+	outputFmt = "csv"
+	assert.Equal(t, []string{
+		"NAME,DOMAINS,VIP,WAF,AGE,TEAM",
+		`shop,shop.example.com,do_not_advertise,disable_waf,<unknown>,"web, payments"`,
+	}, render(false))
+
+	outputFmt = "markdown"
+	assert.Equal(t, []string{
+		"| NAME | DOMAINS | VIP | WAF | AGE | TEAM |",
+		"| --- | --- | --- | --- | --- | --- |",
+		"| shop | shop.example.com | do_not_advertise | disable_waf | <unknown> | web, payments |",
+	}, render(false))
+}
+
+func TestListAllNamespaces(t *testing.T) {
+	var mu sync.Mutex
+	var gotFields []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/web/namespaces":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []map[string]string{{"name": "shop"}, {"name": "secret"}, {"name": "blog"}},
+			})
+		case "/api/config/namespaces/shop/origin_pools":
+			mu.Lock()
+			gotFields = append(gotFields, r.URL.Query().Get("label_filter"))
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []map[string]string{{"name": "web"}, {"name": "api"}},
+			})
+		case "/api/config/namespaces/blog/origin_pools":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []map[string]string{{"name": "wordpress", "namespace": "blog"}},
+			})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "token")
+	client, err := runtime.NewClientFromEnv()
+	require.NoError(t, err)
+
+	rt := ResourceRegistry["origin_pool"]
+	params := map[string]string{"label_filter": "env in (prod)", "page_token": "abc"}
+	result, failures, err := listAllNamespaces(context.Background(), client, rt, params)
+	require.NoError(t, err)
+
+	items, ok := result["items"].([]interface{})
+	require.True(t, ok)
+	var names []string
+	for _, item := range items {
+		m := item.(map[string]interface{})
+		names = append(names, extractNamespace(m)+"/"+extractName(m))
+	}
+	assert.Equal(t, []string{"shop/web", "shop/api", "blog/wordpress"}, names)
+	assert.Equal(t, []string{"env in (prod)"}, gotFields)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, "secret", failures[0].Namespace)
+	assert.Error(t, failures[0].Err)
+}
+
+func TestListAllNamespacesTimeout(t *testing.T) {
+	// Listing all namespaces takes longer than the timeout of a single one
+	const namespaces = 5 * allNamespacesConcurrency
+	oldTimeout := namespaceListTimeout
+	namespaceListTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { namespaceListTimeout = oldTimeout })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/web/namespaces" {
+			var items []interface{}
+			for i := 0; i < namespaces; i++ {
+				items = append(items, map[string]interface{}{"name": "ns-" + strconv.Itoa(i)})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "pool"}}})
+	}))
+	defer server.Close()
+
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "token")
+	client, err := runtime.NewClientFromEnv()
+	require.NoError(t, err)
+
+	result, failures, err := listAllNamespaces(context.Background(), client, ResourceRegistry["origin_pool"], nil)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.Len(t, result["items"], namespaces)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	item := func(name, modified string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":            name,
+			"namespace":       "shop",
+			"system_metadata": map[string]interface{}{"modification_timestamp": modified},
+		}
+	}
+	snapshot := func(items ...map[string]interface{}) *watchSnapshot {
+		list := make([]interface{}, len(items))
+		for i, it := range items {
+			list[i] = it
+		}
+		return newWatchSnapshot(list)
+	}
+
+	tests := []struct {
+		name string
+		prev *watchSnapshot
+		cur  *watchSnapshot
+		want []string
+	}{
+		{name: "initial list", prev: snapshot(), cur: snapshot(item("b", "1"), item("a", "1")), want: []string{"ADDED b", "ADDED a"}},
+		{name: "unchanged", prev: snapshot(item("a", "1")), cur: snapshot(item("a", "1")), want: nil},
+		{name: "modified", prev: snapshot(item("a", "1"), item("b", "1")), cur: snapshot(item("a", "1"), item("b", "2")), want: []string{"MODIFIED b"}},
+		{name: "deleted and added", prev: snapshot(item("c", "1"), item("a", "1")), cur: snapshot(item("b", "1")), want: []string{"ADDED b", "DELETED a", "DELETED c"}},
+		{
+			name: "content change without version",
+			prev: snapshot(map[string]interface{}{"name": "a", "labels": map[string]interface{}{"env": "dev"}}),
+			cur:  snapshot(map[string]interface{}{"name": "a", "labels": map[string]interface{}{"env": "prod"}}),
+			want: []string{"MODIFIED a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, event := range diffSnapshots(tt.prev, tt.cur) {
+				got = append(got, event.Type+" "+extractName(event.Object))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResourceWatcher(t *testing.T) {
+	lists := []string{
+		`{"items":[{"name":"web"}]}`,
+		`{"items":[{"name":"web"},{"name":"api"}]}`,
+		``, // failed refresh
+		`{"items":[{"name":"api"}]}`,
+	}
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, r.URL.Query().Has("report_fields"))
+		body := lists[min(requests, len(lists)-1)]
+		requests++
+		if body == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "token")
+	client, err := runtime.NewClientFromEnv()
+	require.NoError(t, err)
+
+	watcher := &resourceWatcher{
+		client: client,
+		rt:     ResourceRegistry["origin_pool"],
+		ns:     "shop",
+		params: map[string]string{"report_fields": ""},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []string
+	err = watcher.run(ctx, 10*time.Millisecond, func(events []watchEvent) error {
+		for _, event := range events {
+			got = append(got, event.Type+" "+extractName(event.Object))
+		}
+		if len(got) >= 3 {
+			cancel()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ADDED web", "ADDED api", "DELETED web"}, got)
+}
+
+func TestJSONPathSelectors(t *testing.T) {
+	list := func() map[string]interface{} {
+		return map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"name": "web", "spec": map[string]interface{}{"port": float64(443), "domains": []interface{}{"web.example.com", "www.example.com"}}},
+			map[string]interface{}{"name": "api", "spec": map[string]interface{}{"port": float64(8080), "domains": []interface{}{"api.example.com"}}},
+			map[string]interface{}{"name": "db", "spec": map[string]interface{}{"port": float64(80)}},
+		}}
+	}
+	names := func(result map[string]interface{}) []string {
+		var names []string
+		for _, item := range result["items"].([]interface{}) {
+			names = append(names, extractName(item.(map[string]interface{})))
+		}
+		return names
+	}
+
+	for _, tt := range []struct {
+		sortBy string
+		want   []string
+	}{
+		{sortBy: ".spec.port", want: []string{"db", "web", "api"}},
+		{sortBy: "{.name}", want: []string{"api", "db", "web"}},
+		{sortBy: ".spec.domains[0]", want: []string{"db", "api", "web"}},
+	} {
+		t.Run("sort by "+tt.sortBy, func(t *testing.T) {
+			result, err := sortResourcesByField(list(), tt.sortBy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(result))
+		})
+	}
+	_, err := sortResourcesByField(list(), ".items[")
+	assert.Error(t, err)
+
+	for _, tt := range []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "name=api", want: []string{"api"}},
+		{selector: "spec.port!=443", want: []string{"api", "db"}},
+		{selector: "spec.domains[*]=www.example.com", want: []string{"web"}},
+		{selector: "spec.domains[*]!=www.example.com", want: []string{"api", "db"}},
+	} {
+		t.Run("field selector "+tt.selector, func(t *testing.T) {
+			result, err := filterByFieldSelector(list(), tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(result))
+		})
+	}
+}
+
+func TestTranslateLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector      string
+		wantFilter    string
+		wantRemainder []labelCondition
+	}{
+		{selector: "env=prod", wantFilter: "env=prod"},
+		{selector: "env==prod,tier!=db", wantFilter: "env=prod,tier!=db"},
+		{selector: "env in (staging,testing), tier notin (db)", wantFilter: "env in (staging, testing),tier notin (db)"},
+		{selector: "ves.io/app,!legacy", wantFilter: "ves.io/app,!legacy"},
+		{
+			selector:      "env=prod,owner=team a",
+			wantFilter:    "env=prod",
+			wantRemainder: []labelCondition{{key: "owner", operator: "=", values: []string{"team a"}}},
+		},
+		{
+			selector:      "tier in (web,-db)",
+			wantRemainder: []labelCondition{{key: "tier", operator: "in", values: []string{"web", "-db"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			filter, remainder := translateLabelSelector(tt.selector)
+			assert.Equal(t, tt.wantFilter, filter)
+			assert.Equal(t, tt.wantRemainder, remainder)
+		})
+	}
+}
+
+func TestFieldSelectorOperators(t *testing.T) {
+	list := func() map[string]interface{} {
+		return map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"name": "web", "get_spec": map[string]interface{}{"port": float64(443), "domains": []interface{}{"web.example.com"}}},
+			map[string]interface{}{"name": "api", "get_spec": map[string]interface{}{"port": float64(8080), "domains": []interface{}{"api.example.org"}}},
+			map[string]interface{}{"name": "db", "get_spec": map[string]interface{}{"port": float64(80)}},
+		}}
+	}
+
+	tests := []struct {
+		selector string
+		want     []string
+		wantErr  bool
+	}{
+		{selector: "name in (web, db)", want: []string{"web", "db"}},
+		{selector: "name notin (web)", want: []string{"api", "db"}},
+		{selector: "spec.domains[*]=~\\.org$", want: []string{"api"}},
+		{selector: "spec.domains[*]!~^web", want: []string{"api", "db"}},
+		{selector: "spec.port>80", want: []string{"web", "api"}},
+		{selector: "spec.port<=443,name!=db", want: []string{"web"}},
+		{selector: "spec.port>=8080", want: []string{"api"}},
+		{selector: "spec.port<high", wantErr: true},
+		{selector: "name=~[", wantErr: true},
+		{selector: "name in web", wantErr: true},
+		{selector: "name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			result, err := filterByFieldSelector(list(), tt.selector)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, item := range result["items"].([]interface{}) {
+				names = append(names, extractName(item.(map[string]interface{})))
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestExportResource(t *testing.T) {
+	lb := ResourceRegistry["http_loadbalancer"]
+	ns := ResourceRegistry["namespace"]
+
+	tests := []struct {
+		name     string
+		rt       *ResourceType
+		resource map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name: "get response",
+			rt:   lb,
+			resource: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":        "shop",
+					"namespace":   "prod",
+					"uid":         "1234",
+					"labels":      map[string]interface{}{"env": "prod"},
+					"annotations": map[string]interface{}{},
+					"description": "",
+				},
+				"system_metadata": map[string]interface{}{"creation_timestamp": "2024-01-01T00:00:00Z"},
+				"status":          []interface{}{map[string]interface{}{"state": "READY"}},
+				"spec": map[string]interface{}{
+					"domains":     []interface{}{"shop.example.com"},
+					"disable_waf": map[string]interface{}{},
+					"host_name":   "",
+					"routes":      []interface{}{},
+				},
+			},
+			want: map[string]interface{}{
+				"kind": "http_loadbalancer",
+				"metadata": map[string]interface{}{
+					"name":      "shop",
+					"namespace": "prod",
+					"labels":    map[string]interface{}{"env": "prod"},
+				},
+				"spec": map[string]interface{}{
+					"domains":     []interface{}{"shop.example.com"},
+					"disable_waf": map[string]interface{}{},
+				},
+			},
+		},
+		{
+			name: "list item",
+			rt:   lb,
+			resource: map[string]interface{}{
+				"name":        "api",
+				"namespace":   "prod",
+				"description": "public API",
+				"uid":         "5678",
+				"tenant":      "acme",
+				"get_spec":    map[string]interface{}{"no_challenge": map[string]interface{}{}},
+			},
+			want: map[string]interface{}{
+				"kind": "http_loadbalancer",
+				"metadata": map[string]interface{}{
+					"name":        "api",
+					"namespace":   "prod",
+					"description": "public API",
+				},
+				"spec": map[string]interface{}{"no_challenge": map[string]interface{}{}},
+			},
+		},
+		{
+			name: "not namespaced",
+			rt:   ns,
+			resource: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "prod", "namespace": "system"},
+				"spec":     map[string]interface{}{},
+			},
+			want: map[string]interface{}{
+				"kind":     "namespace",
+				"metadata": map[string]interface{}{"name": "prod"},
+				"spec":     map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exportResource(tt.resource, tt.rt))
+		})
+	}
+
+	// Diffs still ignore empty objects
+	diff := normalizeForDiff(tests[0].resource)
+	assert.NotContains(t, diff["spec"], "disable_waf")
+	assert.NotContains(t, diff, "kind")
+
+	// Lists are printed as one YAML document per item
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	err = printManifests([]interface{}{tests[0].resource, tests[1].resource}, lb)
+	os.Stdout = stdout
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(r)
+	require.NoError(t, err)
+	decoder := yaml.NewDecoder(&buf)
+	var names []string
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		names = append(names, doc["metadata"].(map[string]interface{})["name"].(string))
+		assert.Equal(t, "http_loadbalancer", doc["kind"])
+	}
+	assert.Equal(t, []string{"shop", "api"}, names)
+}
+
+func TestPrintStatsTable(t *testing.T) {
+	oldFormat := outputFmt
+	t.Cleanup(func() { outputFmt = oldFormat })
+
+	status := &SiteStatusResponse{
+		Name:       "edge-1",
+		State:      "ONLINE",
+		Conditions: []SiteCondition{{Type: "Ready", Status: "True"}},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "csv", want: "METRIC,VALUE\nconditions[0].status,True\nconditions[0].type,Ready\nname,edge-1\nstate,ONLINE\n"},
+		{format: "csv=SITE:.name,STATE:.state", want: "SITE,STATE\nedge-1,ONLINE\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			outputFmt = tt.format
+
+			r, w, err := os.Pipe()
+			require.NoError(t, err)
+			stdout := os.Stdout
+			os.Stdout = w
+			err = printStatsTable(status, 0)
+			os.Stdout = stdout
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			var buf bytes.Buffer
+			_, err = buf.ReadFrom(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestDescribeResource(t *testing.T) {
+	pool := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "prod", "labels": map[string]interface{}{"team": "shop"}},
+		"spec": map[string]interface{}{
+			"port":           float64(443),
+			"origin_servers": []interface{}{map[string]interface{}{"public_name": map[string]interface{}{"dns_name": "origin.example.com"}}},
+			"healthcheck": []interface{}{
+				map[string]interface{}{"name": "http", "namespace": "prod", "tenant": "acme"},
+				map[string]interface{}{"name": "tcp", "namespace": "prod", "tenant": "acme"},
+			},
+		},
+		"status": []interface{}{map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Validation", "status": "Success", "service_name": "ver"}},
+		}},
+	}
+
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/config/namespaces/prod/origin_pools/web":
+			switch r.URL.Query().Get("response_format") {
+			case "GET_RSP_FORMAT_REFERRING_OBJECTS":
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"referring_objects": []map[string]string{{"kind": "http_loadbalancer", "name": "shop", "namespace": "prod"}},
+				})
+			case "GET_RSP_FORMAT_BROKEN_REFERENCES":
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"deleted_referred_objects": []map[string]string{{"kind": "healthcheck", "name": "tcp", "namespace": "prod"}},
+				})
+			default:
+				_ = json.NewEncoder(w).Encode(pool)
+			}
+		case "/api/data/namespaces/prod/audit_logs":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			gotQuery, _ = body["query"].(string)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"logs": []string{`{"time":"2024-01-01T00:00:00Z","user":"alice@example.com","method":"PUT","rsp_code":"200","req_path":"/config/namespaces/prod/origin_pools/web"}`},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("F5XC_API_URL", server.URL)
+	t.Setenv("F5XC_API_TOKEN", "token")
+	client, err := runtime.NewClientFromEnv()
+	require.NoError(t, err)
+
+	rt := ResourceRegistry["origin_pool"]
+	details := fetchDescribeDetails(context.Background(), client, rt, "prod", "web", pool)
+	require.NoError(t, details.refErr)
+	require.NoError(t, details.eventsErr)
+	assert.Equal(t, []objectRef{
+		{Kind: "healthcheck", Namespace: "prod", Name: "http", Field: "spec.healthcheck[0]"},
+		{Kind: "healthcheck", Namespace: "prod", Name: "tcp", Field: "spec.healthcheck[1]", State: "deleted"},
+	}, details.references)
+	assert.Equal(t, []objectRef{{Kind: "http_loadbalancer", Namespace: "prod", Name: "shop"}}, details.referringBy)
+	assert.Contains(t, gotQuery, `req_path=~".*/namespaces/prod/origin_pools/web(/.*)?"`)
+	assert.Contains(t, gotQuery, `method!="GET"`)
+
+	// Names are matched literally, not as regular expressions
+	assert.Equal(t, `{req_path=~".*/namespaces/prod/origin_pools/web\\.v1(/.*)?", method!="GET"}`, auditQuery("/namespaces/prod/origin_pools/web.v1"))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	err = printDescribe(pool, rt, "web", details)
+	os.Stdout = stdout
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(r)
+	require.NoError(t, err)
+	out := buf.String()
+	for _, want := range []string{
+		"team=shop",
+		"Origins:",
+		"origin.example.com",
+		"Validation",
+		"healthcheck prod/tcp",
+		"(deleted)",
+		"http_loadbalancer prod/shop",
+		"alice@example.com",
+	} {
+		assert.Contains(t, out, want)
+	}
+}
+
+func TestFindObjectRefs(t *testing.T) {
+	spec := map[string]interface{}{
+		"default_route_pools": []interface{}{
+			map[string]interface{}{"pool": map[string]interface{}{"name": "web", "namespace": "prod", "tenant": "acme"}, "weight": float64(1)},
+		},
+		"app_firewall": map[string]interface{}{"name": "waf", "namespace": "shared"},
+		"routes": []interface{}{
+			map[string]interface{}{"simple_route": map[string]interface{}{
+				"origin_pools": []interface{}{map[string]interface{}{"pool": map[string]interface{}{"kind": "origin_pool", "name": "api", "namespace": "prod"}}},
+			}},
+		},
+		// Not references: other fields, no namespace
+		"request_headers_to_add": []interface{}{map[string]interface{}{"name": "x-env", "value": "prod"}},
+		"cookie":                 map[string]interface{}{"name": "session"},
+	}
+
+	assert.Equal(t, []objectRef{
+		{Kind: "app_firewall", Namespace: "shared", Name: "waf", Field: "spec.app_firewall"},
+		{Kind: "origin_pool", Namespace: "prod", Name: "web", Field: "spec.default_route_pools[0].pool"},
+		{Kind: "origin_pool", Namespace: "prod", Name: "api", Field: "spec.routes[0].simple_route.origin_pools[0].pool"},
+	}, findObjectRefs(spec, "spec"))
+}
+
+func TestSplitWaitJSONPath(t *testing.T) {
+	tests := []struct {
+		expr      string
+		wantPath  string
+		wantValue string
+		wantErr   bool
+	}{
+		{expr: "{.status.state}=active", wantPath: "{.status.state}", wantValue: "active"},
+		{expr: `{.status.conditions[?(@.type=="Ready")].status}=True`, wantPath: `{.status.conditions[?(@.type=="Ready")].status}`, wantValue: "True"},
+		{expr: ".status.state=active", wantPath: ".status.state", wantValue: "active"},
+		{expr: "{.status.state}", wantErr: true},
+		{expr: "=active", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, value, err := splitWaitJSONPath(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantValue, value)
+		})
+	}
+}
+
 func TestTotalCommandCount(t *testing.T) {
 	count := countCommands(rootCmd)
 	// Root + version + configure + config(3) + auth(3) + namespace(4) +
@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
+	"github.com/f5/f5xcctl/internal/auth"
 	"github.com/f5/f5xcctl/internal/config"
+	"github.com/f5/f5xcctl/internal/output"
 )
 
 var configCmd = &cobra.Command{
@@ -13,7 +21,18 @@ var configCmd = &cobra.Command{
 	Short: "Manage CLI configuration",
 	Long: `Manage f5xcctl CLI configuration settings.
 
-Use subcommands to get, set, and list configuration values.`,
+Use subcommands to get, set, and list configuration values.
+
+The configuration holds tenants, users and contexts. A context combines a
+tenant, a user (how to authenticate) and defaults such as the namespace;
+--profile selects a context for a single command.
+
+Examples:
+  # Create a context for the shop namespace reusing an existing user
+  f5xcctl config set-context shop --tenant acme --user ci --namespace shop
+
+  # Switch to it
+  f5xcctl config use-context shop`,
 }
 
 var configGetCmd = &cobra.Command{
@@ -21,13 +40,18 @@ var configGetCmd = &cobra.Command{
 	Short: "Get a configuration value",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load("", "")
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		name, err := targetProfile(cfg)
 		if err != nil {
 			return err
 		}
 
 		key := args[0]
-		value, err := cfg.Get(key)
+		value, err := cfg.GetProfileKey(name, key)
 		if err != nil {
 			return err
 		}
@@ -40,18 +64,52 @@ var configGetCmd = &cobra.Command{
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
-	Args:  cobra.ExactArgs(2),
+	Long: `Set a configuration value of the current profile, or of the profile named
+by --profile without switching to it.
+
+Values are validated: URLs must be http(s) URLs, auth-method must be one of
+api-token, certificate, p12, sso or oidc-federation, and certificate, key and
+token files must exist.
+
+A profile with protected set to true asks for the tenant name to be typed
+before apply, delete, patch, edit, label, annotate and the create, update and
+delete commands of the resource groups run; F5XC_CONFIRM_TENANT supplies it
+non-interactively. With read-only set to true, these commands are refused.
+Neither can be bypassed with --yes or --force.
+
+Examples:
+  # Point the current profile at another tenant
+  f5xcctl config set tenant acme
+
+  # Switch the staging profile to P12 authentication
+  f5xcctl config set auth-method p12 --profile staging
+  f5xcctl config set p12-file ~/certs/staging.p12 --profile staging
+
+  # Require typing the tenant name before changing production resources
+  f5xcctl config set protected true --profile prod
+
+  # Refuse all changes through the audit profile
+  f5xcctl config set read-only true --profile audit`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load("", "")
-		if err != nil {
+		cfg, err := config.Load(cfgFile, "")
+		if errors.Is(err, config.ErrConfigNotFound) {
 			// Create new config if none exists
 			cfg = config.NewDefault()
+			cfg.SetPath(config.ResolveConfigPath(cfgFile))
+		} else if err != nil {
+			return err
+		}
+
+		name, err := targetProfile(cfg)
+		if err != nil {
+			return err
 		}
 
 		key := args[0]
 		value := args[1]
 
-		if err := cfg.Set(key, value); err != nil {
+		if err := cfg.SetProfileKey(name, key, value); err != nil {
 			return err
 		}
 
@@ -68,18 +126,22 @@ var configListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all configuration values",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load("", "")
+		cfg, err := config.Load(cfgFile, profile)
 		if err != nil {
 			return err
 		}
 
 		fmt.Printf("Current profile: %s\n\n", cfg.CurrentProfile)
 
+		ctx := cfg.Contexts[cfg.CurrentProfile]
+
 		profile, ok := cfg.Profiles[cfg.CurrentProfile]
 		if !ok {
 			return fmt.Errorf("profile %q not found", cfg.CurrentProfile)
 		}
 
+		fmt.Printf("context-tenant:    %s\n", ctx.Tenant)
+		fmt.Printf("context-user:      %s\n", ctx.User)
 		fmt.Printf("tenant:            %s\n", profile.Tenant)
 		fmt.Printf("api-url:           %s\n", profile.APIURL)
 		fmt.Printf("auth-method:       %s\n", profile.AuthMethod)
@@ -90,6 +152,135 @@ var configListCmd = &cobra.Command{
 	},
 }
 
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Clear a configuration value",
+	Long:  `Clear a configuration value of the current profile, or of the profile named by --profile.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		name, err := targetProfile(cfg)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.UnsetProfileKey(name, args[0]); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		fmt.Printf("Unset %s\n", args[0])
+		return nil
+	},
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Show the effective configuration",
+	Long: `Show the effective settings of the current profile after applying flags and
+environment variables.
+
+Settings are resolved in this order, first match wins:
+  1. flags (--tenant, --api-url, --namespace, --output)
+  2. environment variables (F5XC_TENANT, F5XC_API_URL, F5XC_NAMESPACE,
+     F5XC_OUTPUT, F5XC_AUTH_METHOD, F5XC_CERT_FILE, ...)
+  3. the project file (.f5xcctl.yaml in the working directory or a parent)
+  4. the current profile
+  5. defaults
+
+Examples:
+  # Show effective settings
+  f5xcctl config view
+
+  # Show where each setting came from
+  f5xcctl config view --show-origin`,
+	RunE: runConfigView,
+}
+
+var configViewShowOrigin bool
+
+// ConfigSettingOutput for table display.
+type ConfigSettingOutput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ConfigSettingOriginOutput for table display with --show-origin.
+type ConfigSettingOriginOutput struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+}
+
+func runConfigView(cmd *cobra.Command, args []string) error {
+	project, err := findProject()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadProfile(project)
+	configPath := config.ResolveConfigPath(cfgFile)
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		if err := cfg.CreateProfile(envProfile); err != nil {
+			return err
+		}
+		cfg.CurrentProfile = envProfile
+		configPath += " (not found)"
+	} else if err != nil {
+		return err
+	}
+	cfg.SetProject(project)
+
+	settings, err := config.ResolveSettings(cfg, rootCmd.PersistentFlags())
+	if err != nil {
+		return err
+	}
+
+	if outputFmt == "json" || outputFmt == "yaml" {
+		return output.Print(outputFmt, settings)
+	}
+
+	fmt.Printf("Config file:     %s\n", configPath)
+	if project != nil {
+		fmt.Printf("Project file:    %s\n", project.Path())
+	}
+	fmt.Printf("Current profile: %s\n", cfg.CurrentProfile)
+	if project != nil {
+		if project.Selector != "" {
+			fmt.Printf("Label selector:  %s\n", project.Selector)
+		}
+		if len(project.ManifestDirs) > 0 {
+			fmt.Printf("Manifest dirs:   %s\n", strings.Join(project.ManifestPaths(), ", "))
+		}
+	}
+	fmt.Println()
+
+	if !configViewShowOrigin {
+		rows := make([]ConfigSettingOutput, 0, len(settings))
+		for _, setting := range settings {
+			rows = append(rows, ConfigSettingOutput{Key: setting.Key, Value: setting.Value})
+		}
+		return output.Print("table", rows)
+	}
+
+	rows := make([]ConfigSettingOriginOutput, 0, len(settings))
+	for _, setting := range settings {
+		origin := setting.Origin
+		if setting.Source != "" {
+			origin += " " + setting.Source
+		}
+		rows = append(rows, ConfigSettingOriginOutput{Key: setting.Key, Value: setting.Value, Origin: origin})
+	}
+	return output.Print("table", rows)
+}
+
 var configProfilesCmd = &cobra.Command{
 	Use:   "profiles",
 	Short: "Manage configuration profiles",
@@ -99,12 +290,12 @@ var configProfilesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all profiles",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load("", "")
+		cfg, err := config.Load(cfgFile, "")
 		if err != nil {
 			return err
 		}
 
-		for name := range cfg.Profiles {
+		for _, name := range cfg.ContextNames() {
 			if name == cfg.CurrentProfile {
 				fmt.Printf("* %s (current)\n", name)
 			} else {
@@ -121,17 +312,16 @@ var configProfilesUseCmd = &cobra.Command{
 	Short: "Switch to a different profile",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load("", "")
+		cfg, err := config.Load(cfgFile, "")
 		if err != nil {
 			return err
 		}
 
 		profileName := args[0]
-		if _, ok := cfg.Profiles[profileName]; !ok {
-			return fmt.Errorf("profile %q not found", profileName)
+		if err := cfg.UseContext(profileName); err != nil {
+			return err
 		}
 
-		cfg.CurrentProfile = profileName
 		if err := config.Save(cfg); err != nil {
 			return err
 		}
@@ -141,12 +331,718 @@ var configProfilesUseCmd = &cobra.Command{
 	},
 }
 
+var configProfilesCreateCmd = &cobra.Command{
+	Use:   "create <profile>",
+	Short: "Create a profile",
+	Long: `Create a profile with its own user. Settings not given as flags can be added
+later with 'f5xcctl config set --profile <profile>'.
+
+Examples:
+  # Create a profile for the staging tenant using P12 authentication
+  f5xcctl config profiles create staging --tenant acme-staging \
+    --auth-method p12 --p12-file ~/certs/staging.p12 --default-namespace shop`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigProfilesCreate,
+}
+
+var configProfilesDeleteCmd = &cobra.Command{
+	Use:   "delete <profile>",
+	Short: "Delete a profile and its credentials",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		user := cfg.Contexts[name].User
+		if err := cfg.DeleteProfile(name); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		// Credentials go with the user once no other profile uses it
+		if _, kept := cfg.Users[user]; user != "" && !kept {
+			if err := updateCredentials(cfg, func(creds *config.Credentials) {
+				delete(creds.Profiles, user)
+			}); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Deleted profile %q\n", name)
+		if cfg.CurrentProfile == "" {
+			fmt.Println("Warning: this was the current profile; select another with 'f5xcctl config profiles use'")
+		}
+		return nil
+	},
+}
+
+var configProfilesCopyCmd = &cobra.Command{
+	Use:   "copy <source> <destination>",
+	Short: "Copy a profile and its credentials",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		src, dst := args[0], args[1]
+		srcUser := cfg.Contexts[src].User
+		if err := cfg.CopyProfile(src, dst); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		if err := updateCredentials(cfg, func(creds *config.Credentials) {
+			if profileCreds, ok := creds.Profiles[srcUser]; ok {
+				creds.Profiles[dst] = profileCreds
+			}
+		}); err != nil {
+			return err
+		}
+
+		fmt.Printf("Copied profile %q to %q\n", src, dst)
+		return nil
+	},
+}
+
+var configProfilesRenameCmd = &cobra.Command{
+	Use:   "rename <old-name> <new-name>",
+	Short: "Rename a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		oldName, newName := args[0], args[1]
+		oldUser := cfg.Contexts[oldName].User
+		if err := cfg.RenameProfile(oldName, newName); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		if newUser := cfg.Contexts[newName].User; newUser != oldUser {
+			if err := updateCredentials(cfg, func(creds *config.Credentials) {
+				if profileCreds, ok := creds.Profiles[oldUser]; ok {
+					creds.Profiles[newUser] = profileCreds
+					delete(creds.Profiles, oldUser)
+				}
+			}); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Profile %q renamed to %q\n", oldName, newName)
+		return nil
+	},
+}
+
+// profileCreateKeys are the settings accepted as flags by 'config profiles create'.
+var profileCreateKeys = []struct {
+	key   string
+	usage string
+}{
+	{"tenant", "Tenant name"},
+	{"api-url", "API URL (default https://<tenant>.console.ves.volterra.io)"},
+	{"auth-method", "Authentication method: api-token, certificate, p12, sso, oidc-federation (default api-token)"},
+	{"default-namespace", "Default namespace"},
+	{"output-format", "Default output format"},
+	{"cert-file", "Client certificate file (certificate auth)"},
+	{"key-file", "Client key file (certificate auth)"},
+	{"p12-file", "P12 bundle (p12 auth)"},
+}
+
+var profileCreateUse bool
+
+func runConfigProfilesCreate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cfg, err := config.Load(cfgFile, "")
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		cfg.SetPath(config.ResolveConfigPath(cfgFile))
+	} else if err != nil {
+		return err
+	}
+
+	if err := cfg.CreateProfile(name); err != nil {
+		return err
+	}
+	if err := cfg.SetProfileKey(name, "auth-method", "api-token"); err != nil {
+		return err
+	}
+
+	for _, setting := range profileCreateKeys {
+		if !cmd.Flags().Changed(setting.key) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(setting.key)
+		if err := cfg.SetProfileKey(name, setting.key, value); err != nil {
+			return err
+		}
+	}
+
+	// Derive the API URL from the tenant name like 'f5xcctl configure' does
+	if tenantName, _ := cfg.GetProfileKey(name, "tenant"); tenantName != "" && !cmd.Flags().Changed("api-url") {
+		if err := cfg.SetProfileKey(name, "api-url", fmt.Sprintf("https://%s.console.ves.volterra.io", tenantName)); err != nil {
+			return err
+		}
+	}
+
+	if profileCreateUse || cfg.CurrentProfile == "" {
+		cfg.CurrentProfile = name
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created profile %q\n", name)
+	return nil
+}
+
+// targetProfile returns the profile named by --profile, or the current one.
+func targetProfile(cfg *config.Config) (string, error) {
+	if profile == "" {
+		return cfg.CurrentProfile, nil
+	}
+	if _, ok := cfg.Contexts[profile]; !ok {
+		return "", fmt.Errorf("profile %q not found", profile)
+	}
+	return profile, nil
+}
+
+// updateCredentials applies fn to the credentials that belong to cfg, if any.
+// A credentials file that cannot be read is an error, so that credentials
+// are never silently left behind under an old user name.
+func updateCredentials(cfg *config.Config, fn func(creds *config.Credentials)) error {
+	creds, err := loadCredentials(cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update credentials: %w", err)
+	}
+
+	fn(creds)
+
+	if err := config.SaveCredentials(creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return nil
+}
+
+var configSetContextCmd = &cobra.Command{
+	Use:   "set-context <name>",
+	Short: "Create or modify a context",
+	Long: `Create a context, or modify an existing one, from a tenant, a user and a
+default namespace.
+
+A tenant that does not exist yet is created with the API URL given by
+--api-url (default https://<tenant>.console.ves.volterra.io). A user that does
+not exist yet is created for API token authentication; store its token with
+'f5xcctl auth login'.
+
+Examples:
+  # Create a context for the shop namespace of tenant acme
+  f5xcctl config set-context acme-shop --tenant acme --user ci --namespace shop
+
+  # Change the default namespace of an existing context
+  f5xcctl config set-context acme-shop --namespace checkout`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigSetContext,
+}
+
+var configUseContextCmd = &cobra.Command{
+	Use:   "use-context <name>",
+	Short: "Switch the current context",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.UseContext(args[0]); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		fmt.Printf("Switched to context %q\n", args[0])
+		return nil
+	},
+}
+
+var configRenameContextCmd = &cobra.Command{
+	Use:   "rename-context <old-name> <new-name>",
+	Short: "Rename a context",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.RenameContext(args[0], args[1]); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		fmt.Printf("Context %q renamed to %q\n", args[0], args[1])
+		return nil
+	},
+}
+
+var configDeleteContextCmd = &cobra.Command{
+	Use:   "delete-context <name>",
+	Short: "Delete a context",
+	Long: `Delete a context. The tenant and user it references are kept, since other
+contexts may still use them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile, "")
+		if err != nil {
+			return err
+		}
+
+		wasCurrent := cfg.CurrentProfile == args[0]
+		if err := cfg.DeleteContext(args[0]); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted context %q\n", args[0])
+		if wasCurrent {
+			fmt.Println("Warning: this was the current context; select another with 'f5xcctl config use-context'")
+		}
+		return nil
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration and credentials files",
+	Long: `Upgrade the configuration and credentials files to the current apiVersion.
+
+Files written by older versions of f5xcctl are migrated in memory whenever
+they are read and upgraded on the next save. This command upgrades them
+right away. The original of every migrated file is kept next to it as
+<file>.<apiVersion>.bak (<file>.legacy.bak for unversioned files).
+
+Examples:
+  # Show the pending migrations and the resulting configuration file
+  f5xcctl config migrate --dry-run
+
+  # Migrate the files in place
+  f5xcctl config migrate`,
+	RunE: runConfigMigrate,
+}
+
+var configMigrateDryRun bool
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configPath := config.ResolveConfigPath(cfgFile)
+	credsPath := config.ResolveCredentialsPath(configPath)
+
+	var plans []*config.MigrationPlan
+	for _, file := range []struct {
+		path string
+		plan func(string) (*config.MigrationPlan, error)
+	}{
+		{configPath, config.PlanConfigMigration},
+		{credsPath, config.PlanCredentialsMigration},
+	} {
+		if _, err := os.Stat(file.path); os.IsNotExist(err) {
+			continue
+		}
+		plan, err := file.plan(file.path)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+	}
+
+	if len(plans) == 0 {
+		return fmt.Errorf("%w: %s (run 'f5xcctl configure')", config.ErrConfigNotFound, configPath)
+	}
+
+	for _, plan := range plans {
+		if !plan.Pending() {
+			fmt.Printf("%s is up to date (apiVersion %s)\n", plan.Path, plan.ToVersion)
+			continue
+		}
+
+		from := plan.FromVersion
+		if from == "" {
+			from = "unversioned"
+		}
+		fmt.Printf("%s: %s -> %s\n", plan.Path, from, plan.ToVersion)
+		for _, step := range plan.Steps {
+			fmt.Printf("  - %s\n", step.Description)
+		}
+
+		if configMigrateDryRun {
+			// Never print the credentials file, it holds secrets
+			if plan.Path == configPath {
+				fmt.Printf("\n%s\n", plan.Migrated)
+			}
+			continue
+		}
+
+		if err := plan.Apply(); err != nil {
+			return err
+		}
+		fmt.Printf("  backup written to %s\n", plan.BackupPath())
+	}
+
+	return nil
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export <profile>",
+	Short: "Export a profile as a portable bundle",
+	Long: `Export a profile as a portable bundle that can be imported on another
+machine with 'f5xcctl config import'.
+
+The bundle holds the tenant, authentication settings and defaults of the
+profile. Certificate file paths are reduced to their file names. With
+--include-credentials, the credentials of the profile and its certificate
+files are added, encrypted with a passphrase read from
+F5XC_BUNDLE_PASSPHRASE (or F5XC_BUNDLE_PASSPHRASE_FILE) or prompted for.
+
+Examples:
+  # Export a profile without secrets
+  f5xcctl config export prod > prod.yaml
+
+  # Export a profile with encrypted credentials and certificates
+  f5xcctl config export prod --include-credentials --file prod.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigExport,
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a profile bundle",
+	Long: `Import a profile bundle written by 'f5xcctl config export' ("-" for stdin).
+
+The profile is merged into the local configuration. Importing over an
+existing profile fails unless --name picks another name or --overwrite is
+given; an identical tenant is shared with existing profiles. Certificate
+file paths are re-pointed to --cert-dir (default: the certs directory next
+to the configuration file), where certificates included in the bundle are
+written. Encrypted credentials are imported when the passphrase is given
+in F5XC_BUNDLE_PASSPHRASE (or F5XC_BUNDLE_PASSPHRASE_FILE) or at the prompt.
+
+Examples:
+  # Import a bundle
+  f5xcctl config import prod.yaml
+
+  # Import under another name and switch to it
+  f5xcctl config import prod.yaml --name prod-eu --use
+
+  # Import the settings only
+  f5xcctl config import prod.yaml --skip-credentials`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigImport,
+}
+
+var (
+	configExportFile               string
+	configExportIncludeCredentials bool
+	configImportName               string
+	configImportOverwrite          bool
+	configImportCertDir            string
+	configImportSkipCredentials    bool
+	configImportUse                bool
+)
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile, "")
+	if err != nil {
+		return err
+	}
+
+	var (
+		creds      *config.Credentials
+		passphrase string
+	)
+	if configExportIncludeCredentials {
+		if creds, err = loadOrNewCredentials(cfg); err != nil {
+			return err
+		}
+		if passphrase, err = bundlePassphrase(true); err != nil {
+			return err
+		}
+	}
+
+	bundle, err := cfg.ExportProfile(args[0], creds, passphrase)
+	if err != nil {
+		return err
+	}
+	if tokenFile, _ := cfg.GetProfileKey(args[0], "oidc-token-file"); tokenFile != "" {
+		// The bundle may be written to stdout
+		fmt.Fprintf(os.Stderr, "Warning: oidc-token-file %s is not exported; set it on the importing machine with 'f5xcctl config set oidc-token-file'\n", tokenFile)
+	}
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	if configExportFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(configExportFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	fmt.Printf("Profile %q exported to %s\n", args[0], configExportFile)
+	return nil
+}
+
+func runConfigImport(cmd *cobra.Command, args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	bundle, err := config.ParseBundle(data)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile, "")
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		cfg.SetPath(config.ResolveConfigPath(cfgFile))
+	} else if err != nil {
+		return err
+	}
+
+	opts := config.ImportOptions{
+		Name:      configImportName,
+		Overwrite: configImportOverwrite,
+		CertDir:   configImportCertDir,
+	}
+	if opts.CertDir == "" {
+		opts.CertDir = filepath.Join(filepath.Dir(cfg.Path()), "certs")
+	}
+	if bundle.Secrets != nil && !configImportSkipCredentials {
+		if opts.Passphrase, err = bundlePassphrase(false); err != nil {
+			return err
+		}
+	}
+
+	creds, err := loadOrNewCredentials(cfg)
+	if err != nil {
+		return err
+	}
+	result, err := cfg.ImportBundle(bundle, creds, opts)
+	if err != nil {
+		return err
+	}
+	if configImportUse || cfg.CurrentProfile == "" {
+		cfg.CurrentProfile = result.Profile
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	if result.Credentials {
+		if err := config.SaveCredentials(creds); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Profile %q imported\n", result.Profile)
+	for _, file := range result.Files {
+		fmt.Printf("  wrote %s\n", file)
+	}
+	if result.Credentials {
+		fmt.Println("  credentials imported")
+	}
+	for _, file := range result.Missing {
+		fmt.Printf("Warning: %s does not exist; copy the certificate there before using the profile\n", file)
+	}
+	return nil
+}
+
+// bundlePassphrase returns the passphrase for bundle secrets from
+// F5XC_BUNDLE_PASSPHRASE or F5XC_BUNDLE_PASSPHRASE_FILE, or prompts for it
+// (twice when confirm is set).
+func bundlePassphrase(confirm bool) (string, error) {
+	passphrase, err := auth.ReadSecretEnv("F5XC_BUNDLE_PASSPHRASE")
+	if err != nil || passphrase != "" {
+		return passphrase, err
+	}
+
+	prompt := passwordPrompt()
+	if prompt == nil {
+		return "", fmt.Errorf("a passphrase is required: set F5XC_BUNDLE_PASSPHRASE or run in a terminal")
+	}
+	if passphrase, err = prompt("Bundle passphrase: "); err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	if confirm {
+		again, err := prompt("Repeat passphrase: ")
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+var (
+	setContextTenant       string
+	setContextAPIURL       string
+	setContextUser         string
+	setContextNamespace    string
+	setContextOutputFormat string
+)
+
+func runConfigSetContext(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	if setContextAPIURL != "" {
+		if err := config.ValidateValue("api-url", setContextAPIURL); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.Load(cfgFile, "")
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		cfg.SetPath(config.ResolveConfigPath(cfgFile))
+	} else if err != nil {
+		return err
+	}
+
+	ctx, exists := cfg.Contexts[name]
+
+	if setContextTenant != "" {
+		tenant, ok := cfg.Tenants[setContextTenant]
+		if !ok {
+			tenant = config.Tenant{
+				Name:   setContextTenant,
+				APIURL: fmt.Sprintf("https://%s.console.ves.volterra.io", setContextTenant),
+			}
+		}
+		if setContextAPIURL != "" {
+			tenant.APIURL = setContextAPIURL
+		}
+		cfg.SetTenant(setContextTenant, tenant)
+		ctx.Tenant = setContextTenant
+	} else if setContextAPIURL != "" {
+		if ctx.Tenant == "" {
+			return fmt.Errorf("--api-url requires --tenant for a context without a tenant")
+		}
+		tenant := cfg.Tenants[ctx.Tenant]
+		tenant.APIURL = setContextAPIURL
+		cfg.SetTenant(ctx.Tenant, tenant)
+	}
+
+	if setContextUser != "" {
+		if _, ok := cfg.Users[setContextUser]; !ok {
+			cfg.SetUser(setContextUser, config.User{AuthMethod: "api-token"})
+		}
+		ctx.User = setContextUser
+	}
+	if cmd.Flags().Changed("namespace") {
+		ctx.Namespace = setContextNamespace
+	}
+	if cmd.Flags().Changed("output-format") {
+		ctx.OutputFormat = setContextOutputFormat
+	}
+
+	if err := cfg.SetContext(name, ctx); err != nil {
+		return err
+	}
+	if cfg.CurrentProfile == "" {
+		cfg.CurrentProfile = name
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	if exists {
+		fmt.Printf("Context %q modified\n", name)
+	} else {
+		fmt.Printf("Context %q created\n", name)
+	}
+	return nil
+}
+
 func init() {
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configViewCmd)
 	configCmd.AddCommand(configProfilesCmd)
+	configCmd.AddCommand(configSetContextCmd)
+	configCmd.AddCommand(configUseContextCmd)
+	configCmd.AddCommand(configRenameContextCmd)
+	configCmd.AddCommand(configDeleteContextCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+
+	configSetContextCmd.Flags().StringVar(&setContextTenant, "tenant", "", "Tenant for the context (created if it does not exist)")
+	configSetContextCmd.Flags().StringVar(&setContextAPIURL, "api-url", "", "API URL of the tenant")
+	configSetContextCmd.Flags().StringVar(&setContextUser, "user", "", "User for the context (created if it does not exist)")
+	configSetContextCmd.Flags().StringVar(&setContextNamespace, "namespace", "", "Default namespace for the context")
+	configSetContextCmd.Flags().StringVar(&setContextOutputFormat, "output-format", "", "Default output format for the context")
+
+	configExportCmd.Flags().StringVar(&configExportFile, "file", "", "Write the bundle to a file instead of stdout")
+	configExportCmd.Flags().BoolVar(&configExportIncludeCredentials, "include-credentials", false, "Include credentials and certificate files, encrypted with a passphrase")
+	configImportCmd.Flags().StringVar(&configImportName, "name", "", "Import the profile under another name")
+	configImportCmd.Flags().BoolVar(&configImportOverwrite, "overwrite", false, "Replace an existing profile and certificate files")
+	configImportCmd.Flags().StringVar(&configImportCertDir, "cert-dir", "", "Directory for certificate files (default: certs next to the config file)")
+	configImportCmd.Flags().BoolVar(&configImportSkipCredentials, "skip-credentials", false, "Do not import encrypted credentials")
+	configImportCmd.Flags().BoolVar(&configImportUse, "use", false, "Switch to the imported profile")
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "Show the pending migrations without changing any file")
+	configViewCmd.Flags().BoolVar(&configViewShowOrigin, "show-origin", false, "Show where each setting came from")
 
 	configProfilesCmd.AddCommand(configProfilesListCmd)
 	configProfilesCmd.AddCommand(configProfilesUseCmd)
+	configProfilesCmd.AddCommand(configProfilesCreateCmd)
+	configProfilesCmd.AddCommand(configProfilesDeleteCmd)
+	configProfilesCmd.AddCommand(configProfilesCopyCmd)
+	configProfilesCmd.AddCommand(configProfilesRenameCmd)
+
+	for _, setting := range profileCreateKeys {
+		configProfilesCreateCmd.Flags().String(setting.key, "", setting.usage)
+	}
+	configProfilesCreateCmd.Flags().BoolVar(&profileCreateUse, "use", false, "Switch to the new profile")
 }
@@ -2,14 +2,24 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 
+	"github.com/f5/f5xcctl/internal/auth"
 	"github.com/f5/f5xcctl/internal/config"
+	"github.com/f5/f5xcctl/internal/runtime"
 )
 
 var configureCmd = &cobra.Command{
@@ -17,26 +27,71 @@ var configureCmd = &cobra.Command{
 	Short: "Configure the f5xcctl CLI",
 	Long: `Interactive configuration wizard for the f5xcctl CLI.
 
-This command guides you through setting up your API credentials,
-tenant information, and default preferences.
+This command guides you through setting up your tenant, authentication
+method (API token, P12 bundle or PEM certificate and key) and default
+preferences, and writes them to the profile named by --profile ("default"
+if not given).
+
+P12 bundles are validated with their password and imported into the
+configuration directory; passwords and tokens are kept in the credentials
+file. Before anything is saved, the new settings are tested against the
+API (skip with --no-verify).
+
+For provisioning scripts, --from-env reads the settings from the same
+environment variables as the rest of the CLI (F5XC_TENANT, F5XC_API_URL,
+F5XC_API_TOKEN, F5XC_API_P12_FILE, F5XC_P12_PASSWORD, F5XC_CERT_FILE,
+F5XC_KEY_FILE, F5XC_KEY_PASSWORD, F5XC_NAMESPACE, ...), and --from-file
+reads a YAML file ("-" for stdin) with profile settings and secrets:
+
+  tenant: acme
+  auth-method: p12
+  p12-file: ./acme.p12        # relative to the file
+  p12-password: secret
+  default-namespace: shop
 
 Examples:
   # Run interactive configuration
   f5xcctl configure
 
   # Configure with API token directly
-  f5xcctl configure --api-token YOUR_TOKEN --tenant YOUR_TENANT`,
+  f5xcctl configure --api-token YOUR_TOKEN --tenant YOUR_TENANT
+
+  # Configure a P12 profile, prompting for the password
+  f5xcctl configure --profile prod --tenant acme --p12-file ~/Downloads/acme.p12
+
+  # Configure from the environment in CI
+  F5XC_API_URL=... F5XC_API_TOKEN=... f5xcctl configure --from-env
+
+  # Configure from a file without contacting the API
+  f5xcctl configure --from-file profile.yaml --no-verify`,
 	RunE: runConfigure,
 }
 
 var (
-	configAPIToken string
-	configTenantID string
+	configAPIToken   string
+	configTenantID   string
+	configAuthMethod string
+	configP12File    string
+	configCertFile   string
+	configKeyFile    string
+	configFromEnv    bool
+	configFromFile   string
+	configNoVerify   bool
+	configNoImport   bool
 )
 
 func init() {
 	configureCmd.Flags().StringVar(&configAPIToken, "api-token", "", "API token for authentication")
 	configureCmd.Flags().StringVar(&configTenantID, "tenant", "", "F5XC tenant name")
+	configureCmd.Flags().StringVar(&configAuthMethod, "auth-method", "", "Authentication method: api-token, p12 or certificate")
+	configureCmd.Flags().StringVar(&configP12File, "p12-file", "", "P12 certificate bundle (implies --auth-method p12)")
+	configureCmd.Flags().StringVar(&configCertFile, "cert-file", "", "PEM client certificate (implies --auth-method certificate)")
+	configureCmd.Flags().StringVar(&configKeyFile, "key-file", "", "PEM private key for --cert-file")
+	configureCmd.Flags().BoolVar(&configFromEnv, "from-env", false, "Read settings from environment variables without prompting")
+	configureCmd.Flags().StringVar(&configFromFile, "from-file", "", "Read settings from a YAML file (- for stdin) without prompting")
+	configureCmd.Flags().BoolVar(&configNoVerify, "no-verify", false, "Save without testing the connection to the API")
+	configureCmd.Flags().BoolVar(&configNoImport, "no-import", false, "Reference the P12 file in place instead of copying it into the configuration directory")
+	configureCmd.MarkFlagsMutuallyExclusive("from-env", "from-file")
 }
 
 // readLine reads a line from stdin and trims whitespace including \r\n.
@@ -63,102 +118,486 @@ func readPassword() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fmt.Println() // Print newline since ReadPassword doesn't echo
+	fmt.Fprintln(os.Stderr) // Print newline since ReadPassword doesn't echo
 	return string(password), nil
 }
 
+// passwordPrompt returns a prompt for P12 passwords and key passphrases when
+// stdin is a terminal, or nil so non-interactive runs fail instead of blocking.
+func passwordPrompt() auth.PasswordFunc {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	return func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		return readPassword()
+	}
+}
+
+// configureInput collects the profile settings and secrets written by
+// configure.
+type configureInput struct {
+	settings map[string]string // profile keys, see config.ProfileKeys
+	creds    config.ProfileCredentials
+}
+
+// configureSecretKeys are the secrets accepted by --from-file.
+var configureSecretKeys = []string{"api-token", "p12-password", "key-password"}
+
+// configureFileKeys are the profile settings holding file paths.
+var configureFileKeys = []string{"cert-file", "key-file", "p12-file", "oidc-token-file"}
+
+// configureAuthMethods are the methods the wizard can set up.
+var configureAuthMethods = []string{"api-token", "p12", "certificate"}
+
 func runConfigure(cmd *cobra.Command, args []string) error {
-	reader := bufio.NewReader(os.Stdin)
+	var (
+		input *configureInput
+		err   error
+	)
+	interactive := !configFromEnv && configFromFile == ""
+
+	switch {
+	case configFromEnv:
+		input, err = configureFromEnv()
+	case configFromFile != "":
+		input, err = configureFromFile(configFromFile)
+	default:
+		input = &configureInput{settings: make(map[string]string)}
+	}
+	if err != nil {
+		return err
+	}
+	input.applyFlags(cmd)
 
+	var prompt auth.PasswordFunc
+	if interactive {
+		if err := input.prompt(bufio.NewReader(os.Stdin)); err != nil {
+			return err
+		}
+		prompt = passwordPrompt()
+	}
+
+	if err := input.complete(); err != nil {
+		return err
+	}
+	if err := input.validateCertificates(prompt); err != nil {
+		return err
+	}
+
+	name := profile
+	if name == "" {
+		name = "default"
+	}
+	cfg, creds, err := input.build(name)
+	if err != nil {
+		return err
+	}
+
+	if !configNoVerify {
+		fmt.Printf("Testing connection to %s... ", cfg.GetCurrentProfile().APIURL)
+		if err := testConnectivity(cfg, creds); err != nil {
+			fmt.Println("failed")
+			return fmt.Errorf("connectivity test failed: %w (use --no-verify to save anyway)", err)
+		}
+		fmt.Println("ok")
+	}
+
+	if cfg.GetCurrentProfile().AuthMethod == "p12" && !configNoImport {
+		imported, err := importP12(cfg, name)
+		if err != nil {
+			return err
+		}
+		if err := cfg.SetProfileKey(name, "p12-file", imported); err != nil {
+			return err
+		}
+		fmt.Printf("Imported P12 bundle to %s\n", imported)
+	}
+
+	// Save configuration
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if err := config.SaveCredentials(creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Profile %q saved successfully!\n", name)
+	fmt.Printf("  Config file: %s\n", cfg.Path())
+	fmt.Printf("  Credentials: %s\n", creds.Path())
+	if interactive {
+		fmt.Println()
+		fmt.Println("You can now use f5xcctl commands. Try:")
+		fmt.Println("  f5xcctl namespace list")
+	}
+
+	return nil
+}
+
+// configureFromEnv reads the profile from the environment variables that
+// override profile settings, and the secrets from F5XC_API_TOKEN,
+// F5XC_P12_PASSWORD and F5XC_KEY_PASSWORD (or their _FILE variants).
+func configureFromEnv() (*configureInput, error) {
+	cfg := &config.Config{}
+	if err := cfg.CreateProfile(envProfile); err != nil {
+		return nil, err
+	}
+	cfg.CurrentProfile = envProfile
+
+	settings, err := config.ResolveSettings(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &configureInput{settings: make(map[string]string)}
+	for _, setting := range settings {
+		if setting.Origin == config.OriginEnv {
+			input.settings[setting.Key] = setting.Value
+		}
+	}
+
+	for name, target := range map[string]*string{
+		"F5XC_API_TOKEN":    &input.creds.APIToken,
+		"F5XC_P12_PASSWORD": &input.creds.P12Password,
+		"F5XC_KEY_PASSWORD": &input.creds.KeyPassword,
+	} {
+		if *target, err = auth.ReadSecretEnv(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return input, nil
+}
+
+// configureFromFile reads profile settings and secrets from a YAML file.
+// Relative file paths are relative to the directory of the file.
+func configureFromFile(path string) (*configureInput, error) {
+	var (
+		data []byte
+		err  error
+		dir  string
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		dir, _ = os.Getwd()
+	} else {
+		data, err = os.ReadFile(path)
+		dir = filepath.Dir(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var values map[string]string
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	input := &configureInput{settings: make(map[string]string)}
+	for key, value := range values {
+		switch {
+		case key == "api-token":
+			input.creds.APIToken = value
+		case key == "p12-password":
+			input.creds.P12Password = value
+		case key == "key-password":
+			input.creds.KeyPassword = value
+		case slices.Contains(config.ProfileKeys, key):
+			if slices.Contains(configureFileKeys, key) && value != "" && !filepath.IsAbs(value) {
+				value = filepath.Join(dir, value)
+			}
+			input.settings[key] = value
+		default:
+			valid := append(slices.Clone(config.ProfileKeys), configureSecretKeys...)
+			return nil, fmt.Errorf("unknown key %q in %s (valid keys: %s)", key, path, strings.Join(valid, ", "))
+		}
+	}
+
+	return input, nil
+}
+
+// applyFlags lets configure flags and the global --api-url, --namespace and
+// --output flags override the input.
+func (in *configureInput) applyFlags(cmd *cobra.Command) {
+	flags := map[string]string{
+		"tenant":      "tenant",
+		"auth-method": "auth-method",
+		"p12-file":    "p12-file",
+		"cert-file":   "cert-file",
+		"key-file":    "key-file",
+	}
+	for flag, key := range flags {
+		if cmd.Flags().Changed(flag) {
+			value, _ := cmd.Flags().GetString(flag)
+			in.settings[key] = value
+		}
+	}
+	if cmd.Flags().Changed("api-token") {
+		in.creds.APIToken = configAPIToken
+	}
+
+	global := rootCmd.PersistentFlags()
+	if global.Changed("api-url") {
+		in.settings["api-url"] = apiURL
+	}
+	if global.Changed("namespace") {
+		in.settings["default-namespace"] = namespace
+	}
+	if global.Changed("output") {
+		in.settings["output-format"] = outputFmt
+	}
+}
+
+// prompt asks for the settings that are not set yet.
+func (in *configureInput) prompt(reader *bufio.Reader) error {
 	fmt.Println("F5 Distributed Cloud CLI Configuration")
 	fmt.Println("=======================================")
 	fmt.Println()
 
-	// Get tenant
-	tenantName := configTenantID
-	if tenantName == "" {
-		fmt.Print("Tenant name: ")
-		input, err := readLine(reader)
-		if err != nil {
+	ask := func(key, label, def string) error {
+		if in.settings[key] != "" {
+			return nil
+		}
+		if def != "" {
+			fmt.Printf("%s [%s]: ", label, def)
+		} else {
+			fmt.Printf("%s: ", label)
+		}
+		value, err := readLine(reader)
+		if err != nil && !errors.Is(err, io.EOF) {
 			return fmt.Errorf("failed to read input: %w", err)
 		}
-		tenantName = input
+		if value == "" {
+			value = def
+		}
+		in.settings[key] = value
+		return nil
 	}
 
-	if tenantName == "" {
+	if err := ask("tenant", "Tenant name", ""); err != nil {
+		return err
+	}
+	if in.settings["tenant"] == "" {
 		return fmt.Errorf("tenant name is required")
 	}
+	if err := ask("api-url", "API URL", tenantAPIURL(in.settings["tenant"])); err != nil {
+		return err
+	}
 
-	// Get API token (masked input)
-	apiToken := configAPIToken
-	if apiToken == "" {
-		fmt.Print("API token: ")
-		input, err := readPassword()
-		if err != nil {
-			return fmt.Errorf("failed to read token: %w", err)
+	if in.creds.APIToken != "" && in.settings["auth-method"] == "" {
+		in.settings["auth-method"] = "api-token"
+	}
+	if err := ask("auth-method", "Authentication method ("+strings.Join(configureAuthMethods, "/")+")", in.impliedAuthMethod()); err != nil {
+		return err
+	}
+	switch in.settings["auth-method"] {
+	case "p12":
+		if err := ask("p12-file", "P12 file", ""); err != nil {
+			return err
+		}
+	case "certificate":
+		if err := ask("cert-file", "Certificate file", ""); err != nil {
+			return err
+		}
+		if err := ask("key-file", "Key file", ""); err != nil {
+			return err
+		}
+	case "api-token":
+		if in.creds.APIToken == "" {
+			fmt.Print("API token: ")
+			token, err := readPassword()
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+			in.creds.APIToken = token
+		}
+	}
+
+	if err := ask("default-namespace", "Default namespace", "default"); err != nil {
+		return err
+	}
+	return ask("output-format", "Default output format (table/json/yaml)", "table")
+}
+
+// impliedAuthMethod returns the auth method implied by the files given.
+func (in *configureInput) impliedAuthMethod() string {
+	switch {
+	case in.settings["p12-file"] != "":
+		return "p12"
+	case in.settings["cert-file"] != "":
+		return "certificate"
+	default:
+		return "api-token"
+	}
+}
+
+// complete fills in derived settings and checks that the chosen auth method
+// has what it needs.
+func (in *configureInput) complete() error {
+	if in.settings["tenant"] == "" {
+		return fmt.Errorf("tenant name is required")
+	}
+	if in.settings["api-url"] == "" {
+		in.settings["api-url"] = tenantAPIURL(in.settings["tenant"])
+	}
+	if in.settings["auth-method"] == "" {
+		in.settings["auth-method"] = in.impliedAuthMethod()
+	}
+
+	switch method := in.settings["auth-method"]; method {
+	case "api-token":
+		if in.creds.APIToken == "" {
+			return fmt.Errorf("API token is required")
 		}
-		apiToken = input
+	case "p12":
+		if in.settings["p12-file"] == "" {
+			return fmt.Errorf("p12-file is required for P12 authentication")
+		}
+	case "certificate":
+		if in.settings["cert-file"] == "" || in.settings["key-file"] == "" {
+			return fmt.Errorf("cert-file and key-file are required for certificate authentication")
+		}
+	case "sso", "oidc-federation":
+	default:
+		return fmt.Errorf("invalid auth-method %q: must be one of %s", method, strings.Join(config.AuthMethods, ", "))
 	}
+	return nil
+}
 
-	if apiToken == "" {
-		return fmt.Errorf("API token is required")
+// validateCertificates loads the P12 bundle or PEM certificate and key,
+// asking for the password with prompt (if not nil) until it is correct.
+func (in *configureInput) validateCertificates(prompt auth.PasswordFunc) error {
+	switch in.settings["auth-method"] {
+	case "p12":
+		file := in.settings["p12-file"]
+		for attempt := 1; ; attempt++ {
+			cert, err := auth.LoadP12Certificate(file, in.creds.P12Password)
+			if err == nil {
+				printCertificateSummary(cert.Leaf)
+				return nil
+			}
+			if prompt == nil || attempt > 3 {
+				return err
+			}
+			if attempt > 1 {
+				fmt.Fprintln(os.Stderr, "Incorrect password, try again.")
+			}
+			if in.creds.P12Password, err = prompt(fmt.Sprintf("Password for %s: ", file)); err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+		}
+	case "certificate":
+		certAuth := auth.NewCertAuth(in.settings["cert-file"], in.settings["key-file"])
+		certAuth.KeyPassword = in.creds.KeyPassword
+		certAuth.PasswordPrompt = prompt
+		if _, err := certAuth.GetHTTPClient(); err != nil {
+			return err
+		}
+		in.creds.KeyPassword = certAuth.KeyPassword
+		if leaf, err := auth.ReadPEMCertificate(in.settings["cert-file"]); err == nil {
+			printCertificateSummary(leaf)
+		}
 	}
+	return nil
+}
 
-	// Get default namespace
-	fmt.Print("Default namespace [default]: ")
-	defaultNS, _ := readLine(reader)
-	if defaultNS == "" {
-		defaultNS = "default"
+// printCertificateSummary shows whose certificate was loaded and until when
+// it is valid.
+func printCertificateSummary(leaf *x509.Certificate) {
+	if leaf == nil {
+		return
 	}
+	info := auth.NewCertInfo(leaf)
+	fmt.Printf("Certificate: %s (expires %s)\n", info.Subject, info.NotAfter.Format("2006-01-02"))
+}
 
-	// Get output format preference
-	fmt.Print("Default output format (table/json/yaml) [table]: ")
-	outputFormat, _ := readLine(reader)
-	if outputFormat == "" {
-		outputFormat = "table"
+// build writes the input into the profile name of the existing configuration
+// (or a new one) and its credentials. Nothing is saved.
+func (in *configureInput) build(name string) (*config.Config, *config.Credentials, error) {
+	cfg, err := config.Load(cfgFile, "")
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		cfg.SetPath(config.ResolveConfigPath(cfgFile))
+	} else if err != nil {
+		return nil, nil, err
 	}
 
-	// Build API URL from tenant (without /api suffix - paths include it)
-	apiURL := fmt.Sprintf("https://%s.console.ves.volterra.io", tenantName)
+	if _, ok := cfg.Contexts[name]; !ok {
+		if err := cfg.CreateProfile(name); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	// Create configuration
-	cfg := &config.Config{
-		CurrentProfile: "default",
-		Profiles: map[string]config.Profile{
-			"default": {
-				Tenant:           tenantName,
-				APIURL:           apiURL,
-				AuthMethod:       "api-token",
-				DefaultNamespace: defaultNS,
-				OutputFormat:     outputFormat,
-			},
-		},
+	// Settings of a previous configuration of the profile are replaced,
+	// except for its protection
+	for _, key := range config.ProfileKeys {
+		value := in.settings[key]
+		if value == "" {
+			switch key {
+			case "tenant", "api-url", "protected", "read-only":
+				continue
+			}
+			err = cfg.UnsetProfileKey(name, key)
+		} else {
+			err = cfg.SetProfileKey(name, key, value)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
 	}
+	cfg.CurrentProfile = name
 
-	creds := &config.Credentials{
-		Profiles: map[string]config.ProfileCredentials{
-			"default": {
-				APIToken: apiToken,
-			},
-		},
+	creds, err := loadOrNewCredentials(cfg)
+	if err != nil {
+		return nil, nil, err
 	}
+	creds.Profiles[cfg.CurrentUser()] = in.creds
 
-	// Save configuration
-	if err := config.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	return cfg, creds, nil
+}
+
+// testConnectivity lists namespaces with the new settings.
+func testConnectivity(cfg *config.Config, creds *config.Credentials) error {
+	client, err := runtime.NewClient(cfg, creds, runtime.WithDebug(debug))
+	if err != nil {
+		return err
 	}
 
-	if err := config.SaveCredentials(creds); err != nil {
-		return fmt.Errorf("failed to save credentials: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := client.Get(ctx, "/api/web/namespaces", nil)
+	if err != nil {
+		return err
 	}
+	return resp.Error()
+}
 
-	fmt.Println()
-	fmt.Println("Configuration saved successfully!")
-	fmt.Printf("  Config file: %s\n", config.DefaultConfigPath())
-	fmt.Printf("  Credentials: %s\n", config.DefaultCredentialsPath())
-	fmt.Println()
-	fmt.Println("You can now use f5xcctl commands. Try:")
-	fmt.Println("  f5xcctl namespace list")
+// importP12 copies the P12 bundle of profile name into the certs directory
+// next to the configuration file and returns the new path.
+func importP12(cfg *config.Config, name string) (string, error) {
+	src := cfg.Profiles[name].P12File
+	dst := filepath.Join(filepath.Dir(cfg.Path()), "certs", name+".p12")
+	if src == dst {
+		return dst, nil
+	}
 
-	return nil
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read P12 file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create certs directory: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to import P12 file: %w", err)
+	}
+	return dst, nil
+}
+
+// tenantAPIURL returns the console API URL of a tenant.
+func tenantAPIURL(tenant string) string {
+	return fmt.Sprintf("https://%s.console.ves.volterra.io", tenant)
 }
@@ -0,0 +1,537 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/f5/f5xcctl/internal/runtime"
+)
+
+// Recent events of describe are the latest audit log entries of the object.
+// The audit log query has its own timeout, so that a slow query over the
+// window does not hold up describe.
+const (
+	describeEventsLimit   = 10
+	describeEventsWindow  = 7 * 24 * time.Hour
+	describeEventsTimeout = 10 * time.Second
+)
+
+var showEvents bool
+
+// objectRef is a reference between two configuration objects.
+type objectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Field is the spec path of an outgoing reference
+	Field string
+	// State is "deleted" or "disabled" for broken references
+	State string
+}
+
+// String returns the referenced object as kind namespace/name.
+func (r objectRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// auditEvent is an audit log entry of a request on an object.
+type auditEvent struct {
+	Time    time.Time
+	User    string
+	Method  string
+	Code    string
+	Path    string
+	Message string
+}
+
+// describeDetails is what describe shows beyond the object itself. The
+// errors are reported in place of the sections they belong to, since the
+// object may be described without them.
+type describeDetails struct {
+	references  []objectRef
+	referringBy []objectRef
+	refErr      error
+	events      []auditEvent
+	eventsErr   error
+}
+
+// fetchDescribeDetails resolves the references of a resource and fetches its
+// recent events.
+func fetchDescribeDetails(ctx context.Context, client *runtime.Client, rt *ResourceType, ns, name string, resource map[string]interface{}) *describeDetails {
+	details := &describeDetails{}
+
+	spec, _ := resource["spec"].(map[string]interface{})
+	details.references = findObjectRefs(spec, "spec")
+
+	// The API reports the objects referring to this one, and which of the
+	// objects it refers to are deleted or disabled, in other response formats
+	referring, err := getResponseFormat(ctx, client, rt.GetItemPath(ns, name), "GET_RSP_FORMAT_REFERRING_OBJECTS")
+	if err != nil {
+		details.refErr = err
+	} else {
+		details.referringBy = refsOf(referring["referring_objects"])
+	}
+	if len(details.references) > 0 && details.refErr == nil {
+		broken, err := getResponseFormat(ctx, client, rt.GetItemPath(ns, name), "GET_RSP_FORMAT_BROKEN_REFERENCES")
+		if err != nil {
+			details.refErr = err
+		} else {
+			markBrokenRefs(details.references, refsOf(broken["deleted_referred_objects"]), "deleted")
+			markBrokenRefs(details.references, refsOf(broken["disabled_referred_objects"]), "disabled")
+		}
+	}
+
+	if showEvents {
+		eventsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), describeEventsTimeout)
+		defer cancel()
+		details.events, details.eventsErr = fetchAuditEvents(eventsCtx, client, rt, ns, name)
+	}
+	return details
+}
+
+// getResponseFormat gets an object in one of the response formats of the
+// API.
+func getResponseFormat(ctx context.Context, client *runtime.Client, path, format string) (map[string]interface{}, error) {
+	resp, err := client.Get(ctx, path, url.Values{"response_format": []string{format}})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result, nil
+}
+
+// findObjectRefs returns the references to other objects in a spec, in
+// field order. References are objects with a name and a namespace or tenant
+// and nothing else; their kind is given by the field that holds them
+// (e.g., pool or healthcheck) unless they carry one.
+func findObjectRefs(value interface{}, path string) []objectRef {
+	var refs []objectRef
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			field := path + "." + k
+			if ref, ok := asObjectRef(v[k], k, field); ok {
+				refs = append(refs, ref)
+				continue
+			}
+			if items, ok := v[k].([]interface{}); ok {
+				for i, item := range items {
+					itemField := fmt.Sprintf("%s[%d]", field, i)
+					if ref, ok := asObjectRef(item, k, itemField); ok {
+						refs = append(refs, ref)
+					} else {
+						refs = append(refs, findObjectRefs(item, itemField)...)
+					}
+				}
+				continue
+			}
+			refs = append(refs, findObjectRefs(v[k], field)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			refs = append(refs, findObjectRefs(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return refs
+}
+
+// asObjectRef returns value as a reference held by the field key.
+func asObjectRef(value interface{}, key, field string) (objectRef, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return objectRef{}, false
+	}
+	name, _ := m["name"].(string)
+	_, hasNamespace := m["namespace"]
+	_, hasTenant := m["tenant"]
+	if name == "" || (!hasNamespace && !hasTenant) {
+		return objectRef{}, false
+	}
+	for k := range m {
+		switch k {
+		case "name", "namespace", "tenant", "kind", "uid":
+		default:
+			return objectRef{}, false
+		}
+	}
+
+	kind, _ := m["kind"].(string)
+	if kind == "" {
+		kind = key
+	}
+	namespace, _ := m["namespace"].(string)
+	return objectRef{Kind: refKind(kind), Namespace: namespace, Name: name, Field: field}, true
+}
+
+// refKind returns the resource type name of a reference kind or field, such
+// as origin_pool for pool.
+func refKind(kind string) string {
+	if rt := ResolveResourceType(kind); rt != nil {
+		return rt.Name
+	}
+	return kind
+}
+
+// refsOf converts a list of object references of the API.
+func refsOf(value interface{}) []objectRef {
+	items, _ := value.([]interface{})
+	refs := make([]objectRef, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := m["kind"].(string)
+		namespace, _ := m["namespace"].(string)
+		name, _ := m["name"].(string)
+		refs = append(refs, objectRef{Kind: refKind(kind), Namespace: namespace, Name: name})
+	}
+	return refs
+}
+
+// markBrokenRefs sets the state of the references to the broken objects.
+func markBrokenRefs(refs, broken []objectRef, state string) {
+	for i := range refs {
+		for _, b := range broken {
+			if refs[i].Name == b.Name && refs[i].Namespace == b.Namespace && (b.Kind == "" || refs[i].Kind == b.Kind) {
+				refs[i].State = state
+			}
+		}
+	}
+}
+
+// fetchAuditEvents returns the latest audit log entries of requests that
+// changed the object, newest first.
+func fetchAuditEvents(ctx context.Context, client *runtime.Client, rt *ResourceType, ns, name string) ([]auditEvent, error) {
+	logNamespace := ns
+	if logNamespace == "" {
+		logNamespace = "system"
+	}
+
+	// Audit logs record the request path without the service prefix
+	// (/api/config), so match on the rest of the path
+	objectPath := rt.GetItemPath(ns, name)
+	if rest, ok := strings.CutPrefix(objectPath, "/api/"); ok {
+		if _, after, found := strings.Cut(rest, "/"); found {
+			objectPath = "/" + after
+		}
+	}
+
+	now := time.Now().UTC()
+	body := map[string]interface{}{
+		"namespace":  logNamespace,
+		"query":      auditQuery(objectPath),
+		"start_time": now.Add(-describeEventsWindow).Format(time.RFC3339),
+		"end_time":   now.Format(time.RFC3339),
+		"limit":      describeEventsLimit,
+		"sort":       "DESCENDING",
+	}
+
+	resp, err := client.Post(ctx, fmt.Sprintf("/api/data/namespaces/%s/audit_logs", logNamespace), body)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Logs []string `json:"logs"`
+	}
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode audit logs: %w", err)
+	}
+
+	events := make([]auditEvent, 0, len(result.Logs))
+	for _, entry := range result.Logs {
+		events = append(events, parseAuditEvent(entry))
+	}
+	return events, nil
+}
+
+// auditQuery returns the audit log query for the requests that changed the
+// object at path, or one of its subresources.
+func auditQuery(path string) string {
+	pattern := ".*" + regexp.QuoteMeta(path) + "(/.*)?"
+	return fmt.Sprintf(`{req_path=~%s, method!="GET"}`, strconv.Quote(pattern))
+}
+
+// parseAuditEvent parses an audit log entry. Entries that are not JSON are
+// kept as their message.
+func parseAuditEvent(entry string) auditEvent {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(entry), &fields); err != nil {
+		return auditEvent{Message: entry}
+	}
+
+	str := func(keys ...string) string {
+		for _, k := range keys {
+			switch v := fields[k].(type) {
+			case string:
+				if v != "" {
+					return v
+				}
+			case float64:
+				return fmt.Sprintf("%.0f", v)
+			}
+		}
+		return ""
+	}
+
+	event := auditEvent{
+		User:    str("user"),
+		Method:  str("method"),
+		Code:    str("rsp_code"),
+		Path:    str("req_path"),
+		Message: str("msg", "message"),
+	}
+	if t, err := time.Parse(time.RFC3339Nano, str("time", "@timestamp")); err == nil {
+		event.Time = t
+	}
+	return event
+}
+
+// printDescribe prints the description of a resource: its metadata, a
+// summary of the key spec fields (the printer columns of its type), the
+// spec, its status conditions, the objects it refers to and that refer to
+// it, and its recent events.
+func printDescribe(resource map[string]interface{}, rt *ResourceType, name string, details *describeDetails) error {
+	fmt.Printf("Name:         %s\n", name)
+	fmt.Printf("Kind:         %s\n", rt.Kind)
+
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		if ns, ok := metadata["namespace"].(string); ok && ns != "" {
+			fmt.Printf("Namespace:    %s\n", ns)
+		}
+		if description, ok := metadata["description"].(string); ok && description != "" {
+			fmt.Printf("Description:  %s\n", description)
+		}
+		if labels, ok := metadata["labels"].(map[string]interface{}); ok && len(labels) > 0 {
+			fmt.Println("Labels:")
+			for _, k := range sortedKeys(labels) {
+				fmt.Printf("              %s=%v\n", k, labels[k])
+			}
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok && len(annotations) > 0 {
+			fmt.Println("Annotations:")
+			for _, k := range sortedKeys(annotations) {
+				fmt.Printf("              %s=%v\n", k, annotations[k])
+			}
+		}
+	}
+
+	if sysMeta, ok := resource["system_metadata"].(map[string]interface{}); ok {
+		if uid, ok := sysMeta["uid"].(string); ok {
+			fmt.Printf("UID:          %s\n", uid)
+		}
+		if created, ok := sysMeta["creation_timestamp"].(string); ok {
+			fmt.Printf("Created:      %s\n", created)
+		}
+		if modified, ok := sysMeta["modification_timestamp"].(string); ok {
+			fmt.Printf("Modified:     %s\n", modified)
+		}
+	}
+
+	if len(rt.Columns) > 0 {
+		fmt.Println("\nSummary:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, col := range rt.Columns {
+			col.Width = 0
+			header := strings.ToUpper(col.Header[:1]) + strings.ToLower(col.Header[1:])
+			fmt.Fprintf(tw, "  %s:\t%s\n", header, columnValue(resource, col))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nSpec:")
+	if spec, ok := resource["spec"].(map[string]interface{}); ok {
+		data, _ := yaml.Marshal(spec)
+		// Indent the spec
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	if err := printDescribeStatus(resource["status"]); err != nil {
+		return err
+	}
+
+	if details == nil {
+		return nil
+	}
+
+	fmt.Println("\nReferences:")
+	if err := printObjectRefs(details.references, details.refErr, true); err != nil {
+		return err
+	}
+	fmt.Println("\nReferred By:")
+	if err := printObjectRefs(details.referringBy, details.refErr, false); err != nil {
+		return err
+	}
+
+	if showEvents {
+		fmt.Println("\nEvents:")
+		if err := printAuditEvents(details.events, details.eventsErr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printDescribeStatus prints the status conditions reported for an object.
+// Statuses without conditions are shown as they are.
+func printDescribeStatus(status interface{}) error {
+	fmt.Println("\nStatus:")
+
+	var statuses []interface{}
+	switch s := status.(type) {
+	case []interface{}:
+		statuses = s
+	case map[string]interface{}:
+		statuses = []interface{}{s}
+	}
+	if len(statuses) == 0 {
+		fmt.Println("  <none>")
+		return nil
+	}
+
+	var conditions []map[string]interface{}
+	for _, s := range statuses {
+		m, _ := s.(map[string]interface{})
+		items, _ := m["conditions"].([]interface{})
+		for _, item := range items {
+			if c, ok := item.(map[string]interface{}); ok {
+				conditions = append(conditions, c)
+			}
+		}
+	}
+
+	if len(conditions) == 0 {
+		data, _ := yaml.Marshal(status)
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TYPE\tSTATUS\tREASON\tSERVICE\tLAST UPDATE")
+	for _, c := range conditions {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+			conditionField(c, "type"), conditionField(c, "status"), conditionField(c, "reason"),
+			conditionField(c, "service_name"), conditionField(c, "last_update_time"))
+	}
+	return tw.Flush()
+}
+
+// conditionField returns a field of a status condition, or "-" if unset.
+func conditionField(c map[string]interface{}, key string) string {
+	if v, ok := c[key].(string); ok && v != "" {
+		return v
+	}
+	return "-"
+}
+
+// printObjectRefs prints references; outgoing references show the field
+// that holds them. Outgoing references are read from the spec, so only
+// incoming references are unavailable if the API cannot be asked for them.
+func printObjectRefs(refs []objectRef, err error, outgoing bool) error {
+	if err != nil && !outgoing {
+		fmt.Printf("  <unavailable: %v>\n", err)
+		return nil
+	}
+	if len(refs) == 0 {
+		fmt.Println("  <none>")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, ref := range refs {
+		line := "  " + ref.String()
+		if outgoing {
+			line += "\t" + ref.Field
+			if ref.State != "" {
+				line += "\t(" + ref.State + ")"
+			}
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+// printAuditEvents prints the recent events of an object.
+func printAuditEvents(events []auditEvent, err error) error {
+	if err != nil {
+		fmt.Printf("  <unavailable: %v>\n", err)
+		return nil
+	}
+	if len(events) == 0 {
+		fmt.Printf("  <none in the last %s>\n", formatAge(describeEventsWindow))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  AGE\tUSER\tMETHOD\tCODE\tPATH")
+	for _, e := range events {
+		age := "<unknown>"
+		if !e.Time.IsZero() {
+			age = formatAge(time.Since(e.Time))
+		}
+		path := e.Path
+		if path == "" {
+			path = e.Message
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", age, orDash(e.User), orDash(e.Method), orDash(e.Code), orDash(path))
+	}
+	return tw.Flush()
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -38,10 +38,13 @@ Examples:
   f5xcctl diff -f loadbalancer.yaml
 
   # Diff without color
-  f5xcctl diff -f loadbalancer.yaml --no-color
+  f5xcctl diff -f loadbalancer.yaml --color=never
 
   # Diff multiple resources in a file
-  f5xcctl diff -f configs/`,
+  f5xcctl diff -f configs/
+
+  # Diff the manifest-dirs of the project file (.f5xcctl.yaml)
+  f5xcctl diff`,
 	RunE: runDiff,
 }
 
@@ -49,22 +52,28 @@ func init() {
 	diffCmd.Flags().StringVarP(&diffFilename, "filename", "f", "", "Filename, directory, or URL to files containing the configuration to diff")
 	diffCmd.Flags().BoolVar(&diffServerSide, "server-side", false, "Use server-side diff (if supported)")
 	diffCmd.Flags().BoolVar(&diffNoColor, "no-color", false, "Disable color output")
-	_ = diffCmd.MarkFlagRequired("filename")
+	_ = diffCmd.Flags().MarkDeprecated("no-color", "use --color=never instead")
 
 	rootCmd.AddCommand(diffCmd)
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
-	if diffFilename == "" {
+	if diffNoColor {
+		_ = output.SetColorMode(output.ColorNever)
+	}
+	if diffFilename == "" && !hasProjectManifests() {
 		return fmt.Errorf("filename is required\n\nUsage: f5xcctl diff -f <filename>")
 	}
 
-	resources, err := readResourceFile(diffFilename)
+	resources, err := readManifests(diffFilename)
 	if err != nil {
 		return err
 	}
 
 	if len(resources) == 0 {
+		if diffFilename == "" {
+			return fmt.Errorf("no resources found in manifest-dirs of %s", activeProject.Path())
+		}
 		return fmt.Errorf("no resources found in %s", diffFilename)
 	}
 
@@ -157,6 +166,28 @@ func runDiff(cmd *cobra.Command, args []string) error {
 // normalizeForDiff normalizes a resource for diff comparison
 // Removes fields that change between apply and get (like system_metadata).
 func normalizeForDiff(resource map[string]interface{}) map[string]interface{} {
+	return normalizeResource(resource, false)
+}
+
+// exportResource returns the apply-ready manifest of a resource read from
+// the API: the fields compared by diff plus the kind, which the API does not
+// return, and the empty objects that select oneof options of the spec (e.g.
+// disable_waf: {}).
+func exportResource(resource map[string]interface{}, rt *ResourceType) map[string]interface{} {
+	result := normalizeResource(resource, true)
+	if _, ok := result["kind"]; !ok {
+		result["kind"] = rt.Name
+	}
+	if metadata, ok := result["metadata"].(map[string]interface{}); ok && !rt.Namespaced {
+		delete(metadata, "namespace")
+	}
+	return result
+}
+
+// normalizeResource keeps the user-controllable fields of a resource: its
+// kind, name, namespace, labels, annotations, description and spec. List
+// items carry the metadata at the top level and the spec as get_spec.
+func normalizeResource(resource map[string]interface{}, keepEmpty bool) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	// Copy relevant fields
@@ -164,45 +195,52 @@ func normalizeForDiff(resource map[string]interface{}) map[string]interface{} {
 		result["kind"] = kind
 	}
 
-	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
-		normMetadata := make(map[string]interface{})
-		if name, ok := metadata["name"].(string); ok {
-			normMetadata["name"] = name
-		}
-		if ns, ok := metadata["namespace"].(string); ok {
-			normMetadata["namespace"] = ns
-		}
-		if labels, ok := metadata["labels"].(map[string]interface{}); ok && len(labels) > 0 {
-			normMetadata["labels"] = labels
-		}
-		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok && len(annotations) > 0 {
-			normMetadata["annotations"] = annotations
-		}
-		if description, ok := metadata["description"].(string); ok && description != "" {
-			normMetadata["description"] = description
-		}
+	metadata, ok := resource["metadata"].(map[string]interface{})
+	if !ok || metadata["name"] == nil {
+		metadata = resource
+	}
+	normMetadata := make(map[string]interface{})
+	if name, ok := metadata["name"].(string); ok {
+		normMetadata["name"] = name
+	}
+	if ns, ok := metadata["namespace"].(string); ok {
+		normMetadata["namespace"] = ns
+	}
+	if labels, ok := metadata["labels"].(map[string]interface{}); ok && len(labels) > 0 {
+		normMetadata["labels"] = labels
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok && len(annotations) > 0 {
+		normMetadata["annotations"] = annotations
+	}
+	if description, ok := metadata["description"].(string); ok && description != "" {
+		normMetadata["description"] = description
+	}
+	if len(normMetadata) > 0 {
 		result["metadata"] = normMetadata
 	}
 
-	if spec, ok := resource["spec"].(map[string]interface{}); ok {
-		result["spec"] = normalizeSpec(spec)
+	spec, ok := resource["spec"].(map[string]interface{})
+	if !ok {
+		spec, ok = resource["get_spec"].(map[string]interface{})
+	}
+	if ok {
+		result["spec"] = normalizeSpec(spec, keepEmpty)
 	}
 
 	return result
 }
 
-// normalizeSpec recursively normalizes a spec, removing empty values.
-func normalizeSpec(spec map[string]interface{}) map[string]interface{} {
+// normalizeSpec recursively normalizes a spec, removing empty values. Empty
+// objects are kept with keepEmpty.
+func normalizeSpec(spec map[string]interface{}, keepEmpty bool) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	for k, v := range spec {
 		switch val := v.(type) {
 		case map[string]interface{}:
-			if len(val) > 0 {
-				normalized := normalizeSpec(val)
-				if len(normalized) > 0 {
-					result[k] = normalized
-				}
+			normalized := normalizeSpec(val, keepEmpty)
+			if len(normalized) > 0 || (keepEmpty && len(val) == 0) {
+				result[k] = normalized
 			}
 		case []interface{}:
 			if len(val) > 0 {
@@ -339,35 +377,14 @@ func simpleDiff(oldLines, newLines []string) []diffLine {
 func formatDiffLine(line diffLine) string {
 	switch line.Type {
 	case "+":
-		if diffNoColor {
-			return "+" + line.Content
-		}
-		return "\033[32m+" + line.Content + "\033[0m"
+		return output.Colorize(os.Stdout, output.Green, "+"+line.Content)
 	case "-":
-		if diffNoColor {
-			return "-" + line.Content
-		}
-		return "\033[31m-" + line.Content + "\033[0m"
+		return output.Colorize(os.Stdout, output.Red, "-"+line.Content)
 	default:
 		return " " + line.Content
 	}
 }
 
 func printDiffLine(prefix, content string) {
-	switch prefix {
-	case "+":
-		if diffNoColor {
-			fmt.Printf("+%s\n", content)
-		} else {
-			fmt.Printf("\033[32m+%s\033[0m\n", content)
-		}
-	case "-":
-		if diffNoColor {
-			fmt.Printf("-%s\n", content)
-		} else {
-			fmt.Printf("\033[31m-%s\033[0m\n", content)
-		}
-	default:
-		fmt.Printf(" %s\n", content)
-	}
+	fmt.Println(formatDiffLine(diffLine{Type: prefix, Content: content}))
 }
@@ -131,7 +131,7 @@ func runDNSZoneList(cmd *cobra.Command, args []string) error {
 		return output.Print(outputFmt, list.Items)
 	}
 
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No DNS zones found in namespace %q", ns)
 		return nil
 	}
@@ -155,7 +155,7 @@ func runDNSZoneList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newDNSZoneGetCmd() *cobra.Command {
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/f5/f5xcctl/internal/config"
+)
+
+// mutatingCommands are the top-level commands that change resources.
+var mutatingCommands = map[string]bool{
+	"apply":    true,
+	"create":   true,
+	"replace":  true,
+	"delete":   true,
+	"patch":    true,
+	"edit":     true,
+	"label":    true,
+	"annotate": true,
+}
+
+// mutatingSubcommands change resources when run below a resource group,
+// e.g. 'lb http create' or 'namespace delete'.
+var mutatingSubcommands = map[string]bool{
+	"create": true,
+	"update": true,
+	"upload": true,
+	"delete": true,
+}
+
+// localCommands only change local files and are never guarded.
+var localCommands = map[string]bool{
+	"config":    true,
+	"configure": true,
+	"auth":      true,
+}
+
+// isMutating reports whether cmd changes resources.
+func isMutating(cmd *cobra.Command) bool {
+	path := strings.Fields(cmd.CommandPath())[1:]
+	switch {
+	case len(path) == 0 || localCommands[path[0]]:
+		return false
+	case len(path) == 1:
+		return mutatingCommands[path[0]]
+	default:
+		return mutatingSubcommands[cmd.Name()]
+	}
+}
+
+// isDryRun reports whether cmd was asked only to show what it would change.
+func isDryRun(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("dry-run")
+	return flag != nil && flag.Changed && flag.Value.String() != "false"
+}
+
+// guardProfile enforces the read-only and protected settings of the current
+// profile for commands that change resources. It runs before every command,
+// so the commands' own --yes and --force flags cannot bypass it.
+func guardProfile(cmd *cobra.Command, name string, profile *config.Profile) error {
+	if profile == nil || (!profile.ReadOnly && !profile.Protected) || !isMutating(cmd) || isDryRun(cmd) {
+		return nil
+	}
+
+	action := strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+	if profile.ReadOnly {
+		return fmt.Errorf("profile %q is read-only: '%s' is not allowed (use another profile or 'f5xcctl config unset read-only --profile %s')", name, action, name)
+	}
+
+	// The tenant name is what has to be typed; fall back to the profile name
+	expected := profile.Tenant
+	if expected == "" {
+		expected = name
+	}
+
+	if confirmed := os.Getenv("F5XC_CONFIRM_TENANT"); confirmed != "" {
+		if confirmed != expected {
+			return fmt.Errorf("profile %q is protected: F5XC_CONFIRM_TENANT %q does not match tenant %q", name, confirmed, expected)
+		}
+		return nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("profile %q is protected: '%s' must be confirmed in a terminal or with F5XC_CONFIRM_TENANT=%s", name, action, expected)
+	}
+
+	fmt.Fprintf(os.Stderr, "Profile %q is protected. Type the tenant name (%s) to confirm '%s': ", name, expected, action)
+	var response string
+	_, _ = fmt.Scanln(&response)
+	if strings.TrimSpace(response) != expected {
+		return fmt.Errorf("confirmation failed: %q does not match tenant %q", response, expected)
+	}
+	return nil
+}
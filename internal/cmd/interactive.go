@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/f5/f5xcctl/internal/config"
+	"github.com/f5/f5xcctl/internal/output"
 )
 
 var interactiveCmd = &cobra.Command{
@@ -312,6 +313,9 @@ var flagSuggestions = map[string][]prompt.Suggest{
 		{Text: "--show-labels", Description: "Show labels in output"},
 		{Text: "-w", Description: "Watch for changes"},
 		{Text: "--watch", Description: "Watch for changes"},
+		{Text: "--watch-only", Description: "Watch for changes without listing first"},
+		{Text: "--interval", Description: "Polling interval for --watch"},
+		{Text: "--output-watch-events", Description: "Output watch event types"},
 	},
 	"create": {
 		{Text: "-f", Description: "Filename to create from"},
@@ -416,6 +420,7 @@ var globalFlags = []prompt.Suggest{
 	{Text: "-n", Description: "Target namespace (short)"},
 	{Text: "--debug", Description: "Enable debug output"},
 	{Text: "--profile", Description: "Use specific profile"},
+	{Text: "--as-tenant", Description: "Act in a managed/child tenant"},
 	{Text: "--help", Description: "Show help for command"},
 	{Text: "-h", Description: "Show help for command (short)"},
 }
@@ -430,6 +435,9 @@ var interactiveTenant string
 // interactiveNamespace stores the current namespace for the prompt.
 var interactiveNamespace string
 
+// interactiveAsTenant stores the delegated tenant the shell was started with.
+var interactiveAsTenant string
+
 // getLivePrefix returns the dynamic prompt prefix showing tenant/namespace.
 func getLivePrefix() (string, bool) {
 	ns := interactiveNamespace
@@ -452,6 +460,11 @@ func getLivePrefix() (string, bool) {
 		}
 	}
 
+	// Show the delegated tenant in front of the parent it is accessed through
+	if interactiveAsTenant != "" {
+		tenant = interactiveAsTenant + "@" + tenant
+	}
+
 	return fmt.Sprintf("%s/%s> ", tenant, ns), true
 }
 
@@ -694,7 +707,15 @@ func executor(input string) {
 	}
 
 	// Split input and execute as f5xcctl command
-	args := strings.Fields(input)
+	args, err := config.SplitCommandLine(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	if args, err = expandArgs(commandConfig, args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
 
 	// Reset flags to defaults before each command
 	rootCmd.SetArgs(args)
@@ -717,8 +738,18 @@ func resetCommandState() {
 
 	// Reset output format
 	outputFmt = "table"
+	exportManifests = false
+	rawOutput = false
+	colorMode = output.ColorAuto
+	showEvents = true
 	// Reset namespace to use the interactive default (not the flag value)
 	namespace = interactiveNamespace
+	// Per-command --as-tenant does not change the session's tenant
+	asTenant = interactiveAsTenant
+	// Reset watch flags of get
+	watchOnly = false
+	watchInterval = defaultWatchInterval
+	outputWatchEvents = false
 	// Reset namespace flags
 	nsDescription = ""
 	nsLabels = nil
@@ -765,7 +796,7 @@ func runInteractive(cmd *cobra.Command, args []string) {
 // initInteractivePrompt initializes the tenant and namespace for the prompt.
 func initInteractivePrompt() {
 	// Load config to get tenant
-	cfg, err := config.Load("", "")
+	cfg, err := effectiveConfig()
 	if err == nil && cfg != nil {
 		profile := cfg.GetCurrentProfile()
 		if profile != nil {
@@ -785,4 +816,6 @@ func initInteractivePrompt() {
 	if tenant != "" {
 		interactiveTenant = tenant
 	}
+
+	interactiveAsTenant = asTenant
 }
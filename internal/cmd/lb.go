@@ -160,7 +160,7 @@ func runHTTPLBList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Table output
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No HTTP load balancers found in namespace %q", ns)
 		return nil
 	}
@@ -191,7 +191,7 @@ func runHTTPLBList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newHTTPLBGetCmd() *cobra.Command {
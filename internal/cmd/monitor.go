@@ -132,7 +132,7 @@ func runAlertPolicyList(cmd *cobra.Command, args []string) error {
 		return output.Print(outputFmt, list.Items)
 	}
 
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No alert policies found in namespace %q", ns)
 		return nil
 	}
@@ -159,7 +159,7 @@ func runAlertPolicyList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newAlertPolicyGetCmd() *cobra.Command {
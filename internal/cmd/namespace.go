@@ -166,32 +166,47 @@ type NamespaceTableOutput struct {
 }
 
 func getClient() (*runtime.Client, error) {
-	// First, try to create client from environment variables
-	// This supports F5XC_API_URL, F5XC_API_P12_FILE, F5XC_P12_PASSWORD,
-	// F5XC_CERT_FILE, F5XC_KEY_FILE, and F5XC_API_TOKEN
-	if os.Getenv("F5XC_API_URL") != "" {
-		client, err := runtime.NewClientFromEnv(runtime.WithDebug(debug))
-		if err == nil {
-			return client, nil
-		}
-		// If env-based client creation failed, fall back to config file
-		if debug {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to create client from environment: %v\n", err)
-		}
+	client, err := newProfileClient()
+	if err != nil {
+		return nil, err
 	}
 
-	// Fall back to config file
-	cfg, err := config.Load(cfgFile, profile)
+	// Act in a managed/child tenant using the parent's credentials
+	if asTenant != "" {
+		return delegatedClient(client, asTenant)
+	}
+
+	return client, nil
+}
+
+// newProfileClient creates a client from the effective profile.
+func newProfileClient() (*runtime.Client, error) {
+	cfg, err := loadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w\n\nRun 'f5xcctl configure' to set up the CLI, or set environment variables:\n  F5XC_API_URL, F5XC_API_TOKEN (or F5XC_API_P12_FILE + F5XC_P12_PASSWORD)", err)
 	}
 
-	creds, err := config.LoadCredentials()
+	creds, err := loadCredentials(cfg)
+
+	// An API token in the environment takes precedence over stored credentials
+	if apiToken := os.Getenv("F5XC_API_TOKEN"); apiToken != "" {
+		if err != nil {
+			creds, err = config.NewCredentials(cfg.CredentialsPath()), nil
+		}
+		profileCreds := creds.Profiles[cfg.CurrentUser()]
+		profileCreds.APIToken = apiToken
+		creds.Profiles[cfg.CurrentUser()] = profileCreds
+	}
+
+	// Certificate and federated profiles can run without a credentials file
 	if err != nil {
-		return nil, fmt.Errorf("failed to load credentials: %w\n\nRun 'f5xcctl auth login' to authenticate", err)
+		if p := cfg.GetCurrentProfile(); p == nil || p.AuthMethod == "" || p.AuthMethod == "api-token" {
+			return nil, fmt.Errorf("failed to load credentials: %w\n\nRun 'f5xcctl auth login' to authenticate", err)
+		}
+		creds = nil
 	}
 
-	return runtime.NewClient(cfg, creds)
+	return runtime.NewClient(cfg, creds, runtime.WithDebug(debug), runtime.WithPasswordPrompt(passwordPrompt()))
 }
 
 func runNamespaceList(cmd *cobra.Command, args []string) error {
@@ -229,7 +244,7 @@ func runNamespaceList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Table output
-	if len(listResp.Items) == 0 {
+	if len(listResp.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No namespaces found")
 		return nil
 	}
@@ -242,7 +257,7 @@ func runNamespaceList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func runNamespaceGet(cmd *cobra.Command, args []string) error {
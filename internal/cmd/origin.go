@@ -141,7 +141,7 @@ func runOriginPoolList(cmd *cobra.Command, args []string) error {
 		return output.Print(outputFmt, list.Items)
 	}
 
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No origin pools found in namespace %q", ns)
 		return nil
 	}
@@ -176,7 +176,7 @@ func runOriginPoolList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newOriginPoolGetCmd() *cobra.Command {
@@ -31,6 +31,87 @@ type ResourceType struct {
 
 	// SupportedVerbs lists the verbs supported by this resource
 	SupportedVerbs []string
+
+	// Columns are the resource-specific columns of the table output,
+	// shown between NAME and AGE
+	Columns []PrinterColumn
+}
+
+// PrinterColumn describes a column of the table output of a resource.
+type PrinterColumn struct {
+	// Header is the column header (e.g., "DOMAINS")
+	Header string
+
+	// JSONPaths select the column value (e.g., ".spec.domains"). The values
+	// of all paths that match are shown, comma-separated. A path that
+	// selects an object, such as the empty marker of a one-of choice, shows
+	// as the name of its last field.
+	JSONPaths []string
+
+	// Width truncates longer values; 0 means no limit
+	Width int
+
+	// Priority 0 columns are always shown, others only with -o wide
+	Priority int
+}
+
+// advertiseColumn shows where a load balancer is advertised.
+var advertiseColumn = PrinterColumn{
+	Header: "VIP",
+	JSONPaths: []string{
+		".spec.advertise_on_public_default_vip",
+		".spec.advertise_on_public",
+		".spec.advertise_custom",
+		".spec.do_not_advertise",
+	},
+}
+
+// httpLoadBalancerColumns are the table columns of HTTP load balancers.
+var httpLoadBalancerColumns = []PrinterColumn{
+	{Header: "DOMAINS", JSONPaths: []string{".spec.domains"}, Width: 40},
+	advertiseColumn,
+	{Header: "WAF", JSONPaths: []string{".spec.app_firewall.name", ".spec.disable_waf"}},
+	{Header: "TYPE", JSONPaths: []string{".spec.http", ".spec.https", ".spec.https_auto_cert"}, Priority: 1},
+	{Header: "POOLS", JSONPaths: []string{".spec.default_route_pools[*].pool.name"}, Width: 40, Priority: 1},
+}
+
+// tcpLoadBalancerColumns are the table columns of TCP load balancers.
+var tcpLoadBalancerColumns = []PrinterColumn{
+	{Header: "DOMAINS", JSONPaths: []string{".spec.domains"}, Width: 40},
+	{Header: "PORT", JSONPaths: []string{".spec.listen_port"}},
+	advertiseColumn,
+	{Header: "POOLS", JSONPaths: []string{".spec.origin_pools_weights[*].pool.name"}, Width: 40, Priority: 1},
+}
+
+// originPoolColumns are the table columns of origin pools.
+var originPoolColumns = []PrinterColumn{
+	{Header: "ORIGINS", JSONPaths: []string{
+		".spec.origin_servers[*].public_name.dns_name",
+		".spec.origin_servers[*].public_ip.ip",
+		".spec.origin_servers[*].private_name.dns_name",
+		".spec.origin_servers[*].private_ip.ip",
+		".spec.origin_servers[*].k8s_service.service_name",
+		".spec.origin_servers[*].consul_service.service_name",
+	}, Width: 40},
+	{Header: "PORT", JSONPaths: []string{".spec.port"}},
+	{Header: "ALGORITHM", JSONPaths: []string{".spec.loadbalancer_algorithm"}},
+	{Header: "TLS", JSONPaths: []string{".spec.use_tls", ".spec.no_tls"}, Priority: 1},
+	{Header: "HEALTHCHECKS", JSONPaths: []string{".spec.healthcheck[*].name"}, Width: 40, Priority: 1},
+}
+
+// healthcheckColumns are the table columns of health checks.
+var healthcheckColumns = []PrinterColumn{
+	{Header: "TYPE", JSONPaths: []string{".spec.http_health_check", ".spec.tcp_health_check", ".spec.udp_icmp_health_check"}},
+	{Header: "INTERVAL", JSONPaths: []string{".spec.interval"}},
+	{Header: "TIMEOUT", JSONPaths: []string{".spec.timeout"}, Priority: 1},
+}
+
+// siteColumns are the table columns of sites.
+var siteColumns = []PrinterColumn{
+	{Header: "STATE", JSONPaths: []string{".spec.site_state"}},
+	{Header: "VERSION", JSONPaths: []string{".spec.volterra_software_version"}},
+	{Header: "TYPE", JSONPaths: []string{".spec.site_type"}, Priority: 1},
+	{Header: "OS", JSONPaths: []string{".spec.operating_system_version"}, Priority: 1},
 }
 
 // StandardVerbs are the standard verbs supported by most resources.
@@ -67,6 +148,7 @@ var ResourceRegistry = map[string]*ResourceType{
 		Namespaced:     true,
 		Description:    "HTTP Load Balancer for L7 traffic",
 		SupportedVerbs: AllVerbs,
+		Columns:        httpLoadBalancerColumns,
 	},
 	"tcp_loadbalancer": {
 		Name:           "tcp_loadbalancer",
@@ -79,6 +161,7 @@ var ResourceRegistry = map[string]*ResourceType{
 		Namespaced:     true,
 		Description:    "TCP Load Balancer for L4 traffic",
 		SupportedVerbs: AllVerbs,
+		Columns:        tcpLoadBalancerColumns,
 	},
 	"udp_loadbalancer": {
 		Name:           "udp_loadbalancer",
@@ -105,6 +188,7 @@ var ResourceRegistry = map[string]*ResourceType{
 		Namespaced:     true,
 		Description:    "Origin pool for backend servers",
 		SupportedVerbs: AllVerbs,
+		Columns:        originPoolColumns,
 	},
 
 	// Health Checks
@@ -119,6 +203,7 @@ var ResourceRegistry = map[string]*ResourceType{
 		Namespaced:     true,
 		Description:    "Health check for origin pools",
 		SupportedVerbs: AllVerbs,
+		Columns:        healthcheckColumns,
 	},
 
 	// Security - WAF/Firewall
@@ -241,6 +326,7 @@ var ResourceRegistry = map[string]*ResourceType{
 		Namespaced:     false, // Sites are in system namespace
 		Description:    "Edge site or cloud site",
 		SupportedVerbs: AllVerbs,
+		Columns:        siteColumns,
 	},
 	"virtual_site": {
 		Name:           "virtual_site",
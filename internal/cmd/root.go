@@ -2,6 +2,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -18,13 +19,19 @@ var (
 	outputFmt                string
 	namespace                string
 	tenant                   string
+	asTenant                 string
 	apiURL                   string
 	debug                    bool
 	verbosity                int
 	versionInfo              VersionInfo
 	noHeaders                bool
 	templateFile             string
+	rawOutput                bool
+	colorMode                string
 	allowMissingTemplateKeys bool
+	activeProject            *config.Project
+	activeProfileName        string
+	activeProfile            *config.Profile
 )
 
 // VersionInfo holds version metadata.
@@ -55,10 +62,34 @@ such as load balancers, origin pools, firewalls, certificates, and more.
 When run without arguments, f5xcctl starts in interactive mode with auto-completion.
 
 Configuration:
-  Configure the CLI using 'f5xcctl configure' or set environment variables:
-    F5XC_API_TOKEN    API token for authentication
-    F5XC_TENANT       Tenant name
-    F5XC_NAMESPACE    Default namespace
+  Configure the CLI using 'f5xcctl configure' or set environment variables.
+  Settings are taken from flags, then environment variables, then the
+  current profile, then defaults; 'f5xcctl config view --show-origin' shows
+  where each value came from.
+    F5XC_API_URL       API URL (enough to run without a configuration file)
+    F5XC_API_TOKEN     API token for authentication
+    F5XC_TENANT        Tenant name
+    F5XC_NAMESPACE     Default namespace
+    F5XC_OUTPUT        Default output format
+    F5XC_AUTH_METHOD   Authentication method
+  Every other profile setting can be set as F5XC_<SETTING>, e.g.
+  F5XC_CERT_FILE or F5XC_TOKEN_EXCHANGE.
+
+  A .f5xcctl.yaml file in the working directory or one of its parents
+  pins per-project settings on top of the current profile:
+    profile: prod            # profile to use unless --profile is given
+    namespace: shop          # default namespace
+    selector: team=payments  # default label selector for 'get'
+    manifest-dirs:           # applied and diffed when -f is omitted
+      - manifests
+
+  The configuration file can define command aliases ($1, $2, ... are
+  replaced by arguments) and default flags per command:
+    aliases:
+      lbs: get httplb -A -L env,team --sort-by .metadata.name
+      lb: describe httplb $1
+    defaults:
+      get: --sort-by .metadata.name
 
 Examples:
   # Start interactive mode (default when no args)
@@ -86,13 +117,20 @@ Documentation:
   https://docs.cloud.f5.com/docs/reference/api`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
-	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return initConfig()
-	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Aliases and default flags from the configuration are applied first.
 func Execute() error {
+	commandConfig = loadCommandConfig(os.Args[1:])
+	if commandConfig != nil {
+		registerAliases(commandConfig)
+		args, err := expandArgs(commandConfig, os.Args[1:])
+		if err != nil {
+			return err
+		}
+		rootCmd.SetArgs(args)
+	}
 	return rootCmd.Execute()
 }
 
@@ -101,20 +139,41 @@ func init() {
 	rootCmd.Run = func(cmd *cobra.Command, args []string) {
 		runInteractive(cmd, args)
 	}
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := output.SetColorMode(colorMode); err != nil {
+			return err
+		}
+		if err := initConfig(); err != nil {
+			// The configuration could make the profile read-only or
+			// protected, so commands that change resources fail closed
+			if isMutating(cmd) && !isDryRun(cmd) {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			// Config file is optional for other commands
+			if debug {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		return guardProfile(cmd, activeProfileName, activeProfile)
+	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.f5xcctl/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $F5XC_CONFIG, $XDG_CONFIG_HOME/f5xc/config.yaml or ~/.f5xc/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "configuration profile to use")
 	rootCmd.PersistentFlags().StringVar(&profile, "context", "", "alias for --profile (kubectl compatibility)")
-	rootCmd.PersistentFlags().StringVarP(&outputFmt, "output", "o", "table", `output format: table, wide, json, yaml, name,
-jsonpath='{.field}', custom-columns='NAME:.metadata.name,...', go-template='{{.field}}'`)
+	rootCmd.PersistentFlags().StringVarP(&outputFmt, "output", "o", "table", `output format: table, wide, json, yaml, clean-yaml, name, csv, tsv, markdown,
+jsonpath='{.field}', custom-columns='NAME:.metadata.name,...', go-template='{{.field}}', jq='.field'
+(csv, tsv and markdown also take custom columns, e.g. csv='NAME:.metadata.name,...')`)
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "namespace for the operation")
 	rootCmd.PersistentFlags().StringVar(&tenant, "tenant", "", "F5XC tenant name")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "F5XC API URL")
+	rootCmd.PersistentFlags().StringVar(&asTenant, "as-tenant", "", "act in a managed/child tenant using the current credentials")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug output")
 	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "verbosity level (use -v, -vv, -vvv, or -v=N)")
 	rootCmd.PersistentFlags().BoolVar(&noHeaders, "no-headers", false, "don't print headers in table output")
 	rootCmd.PersistentFlags().StringVar(&templateFile, "template", "", "template file for go-template output format")
+	rootCmd.PersistentFlags().BoolVar(&rawOutput, "raw-output", false, "print string results of jq output without quotes")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", output.ColorAuto, "when to color output: auto (on terminals, unless NO_COLOR is set), always or never")
 	rootCmd.PersistentFlags().BoolVar(&allowMissingTemplateKeys, "allow-missing-template-keys", true, "ignore missing keys in templates")
 
 	// Bind flags to viper
@@ -136,36 +195,123 @@ jsonpath='{.field}', custom-columns='NAME:.metadata.name,...', go-template='{{.f
 	rootCmd.AddCommand(newCertCmd())
 	rootCmd.AddCommand(newDNSCmd())
 	rootCmd.AddCommand(newMonitorCmd())
+	rootCmd.AddCommand(tenantCmd)
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() error {
-	cfg, err := config.Load(cfgFile, profile)
+// effectiveConfig loads the configuration selected by --config and --profile
+// (or the project file) and applies flag, environment and project overrides
+// to the current profile (precedence: flag > environment > project >
+// profile > default). Without a configuration file the settings come from
+// flags, the environment and the project alone.
+func effectiveConfig() (*config.Config, error) {
+	project, err := findProject()
 	if err != nil {
-		// Config file is optional for some commands
-		if debug {
-			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
-		}
+		return nil, err
 	}
 
-	// Store config in context for subcommands
-	if cfg != nil {
-		profile := cfg.GetCurrentProfile()
-		if profile != nil {
-			// Apply config values as defaults (flags take precedence)
-			if namespace == "" && profile.DefaultNamespace != "" {
-				namespace = profile.DefaultNamespace
-			}
-			if tenant == "" && profile.Tenant != "" {
-				tenant = profile.Tenant
-			}
-			if apiURL == "" && profile.APIURL != "" {
-				apiURL = profile.APIURL
-			}
-			if outputFmt == "table" && profile.OutputFormat != "" {
-				outputFmt = profile.OutputFormat
-			}
+	cfg, err := loadProfile(project)
+	if errors.Is(err, config.ErrConfigNotFound) {
+		cfg = &config.Config{}
+		cfg.SetPath(config.ResolveConfigPath(cfgFile))
+		if err := cfg.CreateProfile(envProfile); err != nil {
+			return nil, err
 		}
+		cfg.CurrentProfile = envProfile
+	} else if err != nil {
+		return nil, err
+	}
+	cfg.SetProject(project)
+
+	if _, err := config.ResolveSettings(cfg, rootCmd.PersistentFlags()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// findProject discovers the project file (.f5xcctl.yaml) of the working
+// directory.
+func findProject() (*config.Project, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, nil
+	}
+	return config.FindProject(dir)
+}
+
+// loadProfile loads the configuration with the profile selected by --profile
+// or, failing that, by the project file.
+func loadProfile(project *config.Project) (*config.Config, error) {
+	if profile != "" || project == nil || project.Profile == "" {
+		return config.Load(cfgFile, profile)
+	}
+
+	cfg, err := config.Load(cfgFile, project.Profile)
+	if err != nil && !errors.Is(err, config.ErrConfigNotFound) {
+		return nil, fmt.Errorf("%s: %w", project.Path(), err)
+	}
+	return cfg, err
+}
+
+// envProfile names the profile used when there is no configuration file.
+const envProfile = "env"
+
+// loadConfig returns the effective configuration. A missing configuration
+// file is an error unless F5XC_API_URL provides the tenant.
+func loadConfig() (*config.Config, error) {
+	if _, err := os.Stat(config.ResolveConfigPath(cfgFile)); os.IsNotExist(err) && os.Getenv("F5XC_API_URL") == "" {
+		return config.Load(cfgFile, profile)
+	}
+	return effectiveConfig()
+}
+
+// loadCredentials loads the credentials file that belongs to cfg.
+func loadCredentials(cfg *config.Config) (*config.Credentials, error) {
+	return config.LoadCredentialsFrom(cfg.CredentialsPath())
+}
+
+// loadOrNewCredentials loads the credentials that belong to cfg, or returns an
+// empty set stored next to cfg when there are none yet. Any other error is
+// returned, so that a credentials file that cannot be read is never
+// replaced.
+func loadOrNewCredentials(cfg *config.Config) (*config.Credentials, error) {
+	creds, err := loadCredentials(cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return config.NewCredentials(cfg.CredentialsPath()), nil
+	}
+	return creds, err
+}
+
+// initConfig applies the effective configuration to the global settings.
+// Values already set (by flags, or by interactive mode) are kept. If the
+// configuration cannot be loaded, the error is returned and no profile is
+// active.
+func initConfig() error {
+	activeProfileName, activeProfile = "", nil
+
+	cfg, err := effectiveConfig()
+	if err != nil {
+		return err
+	}
+
+	activeProject = cfg.Project()
+
+	profile := cfg.GetCurrentProfile()
+	if profile == nil {
+		return nil
+	}
+	activeProfileName, activeProfile = cfg.CurrentProfile, profile
+
+	if namespace == "" {
+		namespace = profile.DefaultNamespace
+	}
+	if tenant == "" {
+		tenant = profile.Tenant
+	}
+	if apiURL == "" {
+		apiURL = profile.APIURL
+	}
+	if !rootCmd.PersistentFlags().Changed("output") && profile.OutputFormat != "" {
+		outputFmt = profile.OutputFormat
 	}
 
 	return nil
@@ -189,6 +335,11 @@ func GetTenant() string {
 	return tenant
 }
 
+// GetDelegatedTenant returns the managed/child tenant selected with --as-tenant.
+func GetDelegatedTenant() string {
+	return asTenant
+}
+
 // GetAPIURL returns the API URL.
 func GetAPIURL() string {
 	return apiURL
@@ -226,6 +377,11 @@ func GetTemplateFile() string {
 	return templateFile
 }
 
+// RawOutput returns whether jq output prints strings without quotes.
+func RawOutput() bool {
+	return rawOutput
+}
+
 // AllowMissingTemplateKeys returns whether to ignore missing template keys.
 func AllowMissingTemplateKeys() bool {
 	return allowMissingTemplateKeys
@@ -140,7 +140,7 @@ func runAppFirewallList(cmd *cobra.Command, args []string) error {
 		return output.Print(outputFmt, list.Items)
 	}
 
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && !output.IsTabularFormat(outputFmt) {
 		output.Infof("No app firewalls found in namespace %q", ns)
 		return nil
 	}
@@ -167,7 +167,7 @@ func runAppFirewallList(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	return output.Print("table", tableData)
+	return printTable(tableData)
 }
 
 func newAppFirewallGetCmd() *cobra.Command {
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -265,6 +268,9 @@ func runStatsLBHTTP(cmd *cobra.Command, args []string) error {
 	if outputFmt == "json" || outputFmt == "yaml" {
 		return output.Print(outputFmt, stats)
 	}
+	if output.IsTabularFormat(outputFmt) {
+		return printStatsTable(stats, statsTopN)
+	}
 
 	// Table output
 	fmt.Printf("\n=== HTTP Load Balancer Statistics: %s ===\n\n", name)
@@ -347,6 +353,9 @@ func runStatsSite(cmd *cobra.Command, args []string) error {
 	if outputFmt == "json" || outputFmt == "yaml" {
 		return output.Print(outputFmt, status)
 	}
+	if output.IsTabularFormat(outputFmt) {
+		return printStatsTable(status, 0)
+	}
 
 	// Table output
 	fmt.Printf("\n=== Site Status: %s ===\n\n", name)
@@ -438,6 +447,9 @@ func runStatsSecurity(cmd *cobra.Command, args []string) error {
 	if outputFmt == "json" || outputFmt == "yaml" {
 		return output.Print(outputFmt, stats)
 	}
+	if output.IsTabularFormat(outputFmt) {
+		return printStatsTable(stats, statsTopN)
+	}
 
 	// Table output
 	fmt.Printf("\n=== Security Statistics: %s ===\n\n", name)
@@ -518,6 +530,9 @@ func runStatsAPI(cmd *cobra.Command, args []string) error {
 	if outputFmt == "json" || outputFmt == "yaml" {
 		return output.Print(outputFmt, stats)
 	}
+	if output.IsTabularFormat(outputFmt) {
+		return printStatsTable(stats, statsTopN)
+	}
 
 	// Table output
 	fmt.Printf("\n=== API Statistics: %s ===\n\n", name)
@@ -552,6 +567,59 @@ func runStatsAPI(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printStatsTable prints statistics as a METRIC/VALUE table in CSV, TSV or
+// Markdown. Nested fields become dotted metric names, e.g. response_codes.200
+// or top_endpoints[0].path; lists are cut to their first topN entries unless
+// topN is 0. A column specification, as in -o csv=REQUESTS:.requests_total,
+// instead prints the selected fields as a single row.
+func printStatsTable(stats interface{}, topN int) error {
+	tabular := tabularFormatter()
+	if tabular.Spec != "" {
+		return tabular.Format(os.Stdout, stats)
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistics: %w", err)
+	}
+	var values interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to unmarshal statistics: %w", err)
+	}
+
+	var rows [][]string
+	flattenStats("", values, topN, &rows)
+	return tabular.WriteTable(os.Stdout, []string{"METRIC", "VALUE"}, rows)
+}
+
+// flattenStats appends a row for every leaf value below prefix.
+func flattenStats(prefix string, value interface{}, topN int, rows *[][]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			name := k
+			if prefix != "" {
+				name = prefix + "." + k
+			}
+			flattenStats(name, v[k], topN, rows)
+		}
+	case []interface{}:
+		for i, item := range v {
+			if topN > 0 && i >= topN {
+				break
+			}
+			flattenStats(fmt.Sprintf("%s[%d]", prefix, i), item, topN, rows)
+		}
+	default:
+		*rows = append(*rows, []string{prefix, output.FormatJSONPathValue(v)})
+	}
+}
+
 // formatBytes formats bytes into human-readable format.
 func formatBytes(bytes int64) string {
 	const unit = 1024
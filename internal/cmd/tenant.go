@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/f5/f5xcctl/internal/output"
+	"github.com/f5/f5xcctl/internal/runtime"
+)
+
+// Tenant management API paths.
+const (
+	managedTenantsPath = "/api/web/namespaces/system/managed_tenants_by_user"
+	childTenantsPath   = "/api/web/namespaces/system/partner-management/child_tenants"
+)
+
+var tenantCmd = &cobra.Command{
+	Use:     "tenant",
+	Aliases: []string{"tenants"},
+	Short:   "Manage delegated tenant access",
+	Long: `Manage MSP-style access to managed and child tenants.
+
+Managed tenants grant users of this tenant delegated access through group
+assignments. Partner tenants additionally own child tenants. Use the global
+--as-tenant flag to run any command in one of these tenants with the current
+profile's credentials.
+
+Examples:
+  # List managed tenants you have delegated access to
+  f5xcctl tenant list
+
+  # List child tenants of this partner tenant
+  f5xcctl tenant list --children
+
+  # List load balancers in a child tenant
+  f5xcctl get httplb -n shop --as-tenant child-a`,
+}
+
+var tenantListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List managed or child tenants",
+	Long:  `List the managed tenants (or, with --children, the child tenants) the current user can access.`,
+	RunE:  runTenantList,
+}
+
+var tenantListChildren bool
+
+func init() {
+	tenantListCmd.Flags().BoolVar(&tenantListChildren, "children", false, "List child tenants of this partner tenant")
+
+	tenantCmd.AddCommand(tenantListCmd)
+}
+
+// TenantAccessInfo describes access to a managed or child tenant.
+type TenantAccessInfo struct {
+	Name         string `json:"name"`
+	TenantStatus string `json:"tenant_status,omitempty"`
+	Link         struct {
+		Href string `json:"href,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"link,omitempty"`
+	Groups []interface{} `json:"groups,omitempty"`
+}
+
+// TenantAccessListResponse represents the managed/child tenant list response.
+type TenantAccessListResponse struct {
+	AccessConfig []TenantAccessInfo `json:"access_config"`
+	NextPage     string             `json:"next_page,omitempty"`
+}
+
+// TenantTableOutput for table display.
+type TenantTableOutput struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	URL    string `json:"url"`
+}
+
+func runTenantList(cmd *cobra.Command, args []string) error {
+	// Listing always happens from the parent tenant
+	client, err := newProfileClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	path := managedTenantsPath
+	kind := "managed tenants"
+	if tenantListChildren {
+		path = childTenantsPath
+		kind = "child tenants"
+	}
+
+	tenants, err := listTenantAccess(ctx, client, path)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+
+	if outputFmt == "json" || outputFmt == "yaml" {
+		return output.Print(outputFmt, tenants)
+	}
+
+	if len(tenants) == 0 && !output.IsTabularFormat(outputFmt) {
+		output.Infof("No %s found", kind)
+		return nil
+	}
+
+	tableData := make([]TenantTableOutput, 0, len(tenants))
+	for _, t := range tenants {
+		tableData = append(tableData, TenantTableOutput{
+			Name:   t.Name,
+			Status: strings.TrimPrefix(t.TenantStatus, "TENANT_STATUS_"),
+			URL:    tenantBaseURL(t, client.BaseURL()),
+		})
+	}
+
+	return printTable(tableData)
+}
+
+// listTenantAccess fetches all pages of a managed/child tenant list.
+func listTenantAccess(ctx context.Context, client *runtime.Client, path string) ([]TenantAccessInfo, error) {
+	var tenants []TenantAccessInfo
+	query := url.Values{}
+
+	for {
+		resp, err := client.Get(ctx, path, query)
+		if err != nil {
+			return nil, err
+		}
+		if err := resp.Error(); err != nil {
+			return nil, err
+		}
+
+		var page TenantAccessListResponse
+		if err := resp.DecodeJSON(&page); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		tenants = append(tenants, page.AccessConfig...)
+
+		if page.NextPage == "" {
+			return tenants, nil
+		}
+		query.Set("page_start", page.NextPage)
+	}
+}
+
+// tenantURLCache caches resolved tenant URLs for the life of the process,
+// so interactive sessions only resolve each tenant once. URLs are cached per
+// parent tenant, since the profile can change between commands.
+type tenantURLCache struct {
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+func (c *tenantURLCache) get(parentURL, name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.urls[parentURL+" "+name]
+	return u, ok
+}
+
+func (c *tenantURLCache) set(parentURL, name, baseURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.urls == nil {
+		c.urls = make(map[string]string)
+	}
+	c.urls[parentURL+" "+name] = baseURL
+}
+
+var delegatedTenantURLs tenantURLCache
+
+// delegatedClient returns a client that acts in the named managed or child
+// tenant using the credentials of client.
+func delegatedClient(client *runtime.Client, name string) (*runtime.Client, error) {
+	if baseURL, ok := delegatedTenantURLs.get(client.BaseURL(), name); ok {
+		return client.WithBaseURL(baseURL), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	managed, err := listTenantAccess(ctx, client, managedTenantsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tenant %q: %w", name, err)
+	}
+	// Child tenants are only visible to partner tenants; a 403 here is expected otherwise
+	children, err := listTenantAccess(ctx, client, childTenantsPath)
+	var apiErr *runtime.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.IsForbidden()) {
+		return nil, fmt.Errorf("failed to resolve tenant %q: %w", name, err)
+	}
+
+	for _, t := range append(managed, children...) {
+		if t.Name != name {
+			continue
+		}
+		baseURL := tenantBaseURL(t, client.BaseURL())
+		delegatedTenantURLs.set(client.BaseURL(), name, baseURL)
+		return client.WithBaseURL(baseURL), nil
+	}
+
+	return nil, fmt.Errorf("tenant %q is not a managed or child tenant you can access\n\nRun 'f5xcctl tenant list' to see available tenants", name)
+}
+
+// tenantBaseURL returns the API base URL for a delegated tenant, preferring
+// the console link returned by the API and otherwise deriving it from the
+// parent tenant's URL. Links are only used if they point to a tenant of the
+// parent's domain, since the parent's credentials are sent there.
+func tenantBaseURL(t TenantAccessInfo, parentURL string) string {
+	if t.Link.Href != "" {
+		if u, err := url.Parse(t.Link.Href); err == nil && sameTenantDomain(u, parentURL) {
+			return u.Scheme + "://" + u.Host
+		}
+	}
+	return deriveTenantURL(parentURL, t.Name)
+}
+
+// sameTenantDomain reports whether u is the console of a tenant in the
+// domain of the parent tenant's console, e.g.
+// https://child.console.ves.volterra.io for
+// https://parent.console.ves.volterra.io.
+func sameTenantDomain(u *url.URL, parentURL string) bool {
+	parent, err := url.Parse(parentURL)
+	if err != nil || u.Scheme != parent.Scheme {
+		return false
+	}
+	_, parentDomain, ok := strings.Cut(parent.Host, ".")
+	if !ok {
+		return false
+	}
+	label, domain, ok := strings.Cut(u.Host, ".")
+	return ok && label != "" && domain == parentDomain
+}
+
+// deriveTenantURL replaces the tenant label of the parent's console host,
+// e.g. https://parent.console.ves.volterra.io -> https://child.console.ves.volterra.io.
+func deriveTenantURL(parentURL, name string) string {
+	u, err := url.Parse(parentURL)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("https://%s.console.ves.volterra.io", name)
+	}
+
+	labels := strings.SplitN(u.Host, ".", 2)
+	if len(labels) < 2 {
+		return fmt.Sprintf("https://%s.console.ves.volterra.io", name)
+	}
+
+	return u.Scheme + "://" + name + "." + labels[1]
+}
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
-	"os/signal"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -38,9 +40,10 @@ var (
 	limit     int
 	pageToken string
 	// Get command flags.
-	ignoreNotFound bool
-	labelColumns   []string
-	sortBy         string
+	ignoreNotFound  bool
+	labelColumns    []string
+	sortBy          string
+	exportManifests bool
 	// Delete command flags.
 	deleteAll bool
 )
@@ -56,6 +59,8 @@ var getCmd = &cobra.Command{
 
 Prints a table of the most important information about the specified resources.
 You can filter the list using a label selector or get a specific resource by name.
+Label selectors are evaluated by the API where possible; field selectors match
+any field of the resources, including their spec.
 
 Examples:
   # List all HTTP load balancers in the default namespace
@@ -73,6 +78,10 @@ Examples:
   # Get output in YAML format
   f5xcctl get httplb my-lb -o yaml
 
+  # Export apply-ready manifests without server-managed fields
+  f5xcctl get httplb my-lb --export > my-lb.yaml
+  f5xcctl get httplb -n production -o clean-yaml > production.yaml
+
   # List with labels shown
   f5xcctl get httplb --show-labels
 
@@ -88,6 +97,31 @@ Examples:
   # JSONPath output - extract specific fields
   f5xcctl get httplb -o jsonpath='{.items[*].metadata.name}'
 
+  # JSONPath with filters and range
+  f5xcctl get op -o jsonpath='{range .items[?(@.spec.port==443)]}{.metadata.name}{"\n"}{end}'
+
+  # Filter by labels, including set-based selectors
+  f5xcctl get httplb -l 'env in (staging,testing),!legacy'
+
+  # Filter by fields
+  f5xcctl get op --field-selector 'spec.port>1024,metadata.name=~^api-'
+
+  # Sort by a JSONPath expression
+  f5xcctl get httplb --sort-by='{.spec.domains[0]}'
+
+  # jq output - transform resources with a jq filter
+  f5xcctl get httplb -o jq='.items[] | {name: .metadata.name, domains: .spec.domains}'
+
+  # jq output with raw strings, one name per line
+  f5xcctl get httplb -o jq='.items[].metadata.name' --raw-output
+
+  # CSV, TSV or Markdown output of the printer columns
+  f5xcctl get httplb -o csv -L env > inventory.csv
+  f5xcctl get op -o markdown
+
+  # CSV with custom columns
+  f5xcctl get httplb -o csv=NAME:.metadata.name,DOMAINS:.spec.domains[*]
+
   # Custom columns output
   f5xcctl get httplb -o custom-columns=NAME:.metadata.name,NAMESPACE:.metadata.namespace
 
@@ -98,7 +132,13 @@ Examples:
   f5xcctl get httplb -o go-template --template=my-template.tmpl
 
   # Table without headers (useful for scripting)
-  f5xcctl get httplb --no-headers`,
+  f5xcctl get httplb --no-headers
+
+  # Watch load balancers, showing what changed
+  f5xcctl get httplb -w --output-watch-events
+
+  # Stream changes as JSON, one event per line, polling every 10 seconds
+  f5xcctl get httplb -w --watch-only -o json --output-watch-events --interval 10s`,
 	Args:              cobra.MinimumNArgs(1),
 	RunE:              runGet,
 	ValidArgsFunction: completeResourceTypes,
@@ -190,7 +230,10 @@ Examples:
   f5xcctl apply -f ./configs/ -R
 
   # Dry run - show what would be applied
-  f5xcctl apply -f loadbalancer.yaml --dry-run`,
+  f5xcctl apply -f loadbalancer.yaml --dry-run
+
+  # Apply the manifest-dirs of the project file (.f5xcctl.yaml)
+  f5xcctl apply`,
 	RunE: runApply,
 }
 
@@ -223,15 +266,24 @@ var describeCmd = &cobra.Command{
 	Short: "Show details of a specific resource",
 	Long: `Show detailed information about a specific resource.
 
-Prints a detailed description of the selected resource, including
-related resources, events, and status conditions.
+Prints a detailed description of the selected resource: its metadata, a
+summary of its key spec fields, the spec, its status conditions, the objects
+it refers to (e.g., the origin pools of a load balancer, flagging deleted or
+disabled ones), the objects referring to it (e.g., the load balancers using
+an origin pool), and the changes recorded in the audit log in the last 7 days.
 
 Examples:
   # Describe a load balancer
   f5xcctl describe http_loadbalancer my-lb -n production
 
   # Describe using short name
-  f5xcctl describe httplb my-lb -n production`,
+  f5xcctl describe httplb my-lb -n production
+
+  # Find the load balancers using an origin pool
+  f5xcctl describe pool my-pool -n production
+
+  # Describe without querying the audit log
+  f5xcctl describe httplb my-lb -n production --show-events=false`,
 	Args: cobra.ExactArgs(2),
 	RunE: runDescribe,
 }
@@ -289,14 +341,21 @@ func init() {
 	// GET flags
 	getCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List resources across all namespaces")
 	getCmd.Flags().StringVarP(&labelSelector, "selector", "l", "", "Label selector (e.g., 'env=prod,team=platform')")
-	getCmd.Flags().StringVar(&fieldSelector, "field-selector", "", "Field selector (e.g., 'metadata.name=my-lb')")
+	getCmd.Flags().StringVar(&fieldSelector, "field-selector", "", "Field selector over resource fields, with =, !=, in, notin, =~, !~, <, <=, >, >= (e.g., 'spec.port>1024')")
 	getCmd.Flags().BoolVar(&showLabels, "show-labels", false, "Show labels in output")
 	getCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Watch for changes")
+	getCmd.Flags().BoolVar(&watchOnly, "watch-only", false, "Watch for changes without listing the current resources first")
+	getCmd.Flags().DurationVar(&watchInterval, "interval", defaultWatchInterval, "Polling interval for --watch")
+	getCmd.Flags().BoolVar(&outputWatchEvents, "output-watch-events", false, "Output watch event types (ADDED, MODIFIED, DELETED) with --watch")
 	getCmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of resources to list (0 = unlimited)")
 	getCmd.Flags().StringVar(&pageToken, "page-token", "", "Token for paginated results (provided in previous response)")
 	getCmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", false, "Treat 'resource not found' as successful retrieval (exit code 0)")
 	getCmd.Flags().StringSliceVarP(&labelColumns, "label-columns", "L", nil, "Show specific labels as columns (e.g., -L env,team)")
 	getCmd.Flags().StringVar(&sortBy, "sort-by", "", "Sort output by JSONPath expression (e.g., '.metadata.name')")
+	getCmd.Flags().BoolVar(&exportManifests, "export", false, "Print apply-ready manifests without server-managed fields (same as -o clean-yaml)")
+
+	// DESCRIBE flags
+	describeCmd.Flags().BoolVar(&showEvents, "show-events", true, "Show the recent audit log entries of the resource")
 
 	// CREATE flags
 	createCmd.Flags().StringVarP(&filename, "filename", "f", "", "Filename, directory, or URL to files to create")
@@ -313,10 +372,9 @@ func init() {
 	deleteCmd.Flags().StringVarP(&labelSelector, "selector", "l", "", "Label selector for filtering resources to delete (e.g., 'env=prod')")
 
 	// APPLY flags
-	applyCmd.Flags().StringVarP(&filename, "filename", "f", "", "Filename, directory, or URL to files (required)")
+	applyCmd.Flags().StringVarP(&filename, "filename", "f", "", "Filename, directory, or URL to files (required unless .f5xcctl.yaml sets manifest-dirs)")
 	applyCmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Process the directory recursively")
 	applyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print what would be applied")
-	_ = applyCmd.MarkFlagRequired("filename")
 
 	// REPLACE flags
 	replaceCmd.Flags().StringVarP(&filename, "filename", "f", "", "Filename to replace resource from (required)")
@@ -358,6 +416,13 @@ func runGet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown resource type: %s\n\nUse 'f5xcctl api-resources' to list available resource types", resourceType)
 	}
 
+	if exportManifests {
+		if cmd.Flags().Changed("output") && outputFmt != "yaml" && outputFmt != "clean-yaml" {
+			return fmt.Errorf("--export only supports YAML output, got -o %s", outputFmt)
+		}
+		outputFmt = "clean-yaml"
+	}
+
 	client, err := getClient()
 	if err != nil {
 		return err
@@ -371,6 +436,43 @@ func runGet(cmd *cobra.Command, args []string) error {
 		ns = "default"
 	}
 
+	// The project file may set a default selector for lists
+	if !cmd.Flags().Changed("selector") && activeProject != nil && activeProject.Selector != "" {
+		labelSelector = activeProject.Selector
+	}
+
+	// Build query params
+	params := make(map[string]string)
+
+	// Label selector: the API evaluates what it can express, the remaining
+	// conditions are checked client-side
+	var labelRemainder []labelCondition
+	if labelSelector != "" {
+		var filter string
+		filter, labelRemainder = translateLabelSelector(labelSelector)
+		if filter != "" {
+			params["label_filter"] = filter
+		}
+	}
+
+	// Printer columns and field selectors need the spec of the items
+	if wantsReportFields(rt) || fieldSelector != "" {
+		params["report_fields"] = ""
+	}
+
+	// Pagination parameters
+	if limit > 0 {
+		params["page_size"] = fmt.Sprintf("%d", limit)
+	}
+	if pageToken != "" {
+		params["page_token"] = pageToken
+	}
+
+	// Watch mode
+	if watchFlag || watchOnly {
+		return watchResources(client, rt, ns, resourceName, params, labelRemainder)
+	}
+
 	// If getting a specific resource
 	if resourceName != "" {
 		path := rt.GetItemPath(ns, resourceName)
@@ -399,69 +501,52 @@ func runGet(cmd *cobra.Command, args []string) error {
 		return printResource(result, rt)
 	}
 
-	// Watch mode
-	if watchFlag {
-		return watchResources(client, rt, ns, resourceName)
-	}
-
 	// List resources
+	var (
+		result   map[string]interface{}
+		failures []namespaceFailure
+	)
 	if allNamespaces && rt.Namespaced {
-		return listAllNamespaces(ctx, client, rt)
-	}
-
-	path := rt.GetAPIPath(ns)
-
-	// Build query params
-	params := make(map[string]string)
-
-	// Label selector
-	if labelSelector != "" {
-		params["label_filter"] = convertLabelSelector(labelSelector)
-	}
-
-	// Pagination parameters
-	if limit > 0 {
-		params["page_size"] = fmt.Sprintf("%d", limit)
-	}
-	if pageToken != "" {
-		params["page_token"] = pageToken
+		ns = ""
+		// Every namespace has its own timeout
+		result, failures, err = listAllNamespaces(context.Background(), client, rt, params)
+	} else {
+		result, err = listResources(ctx, client, rt, ns, params)
 	}
-
-	resp, err := client.Get(ctx, path, convertToURLValues(params))
 	if err != nil {
-		return fmt.Errorf("failed to list %s: %w", rt.Plural, err)
-	}
-	if err := resp.Error(); err != nil {
 		return err
 	}
 
-	var result map[string]interface{}
-	if err := resp.DecodeJSON(&result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Apply client-side label filtering if needed (fallback)
-	if labelSelector != "" {
-		result = filterByLabelSelector(result, labelSelector)
-	}
+	// Apply the label conditions the API cannot evaluate
+	result = filterByLabelConditions(result, labelRemainder)
 
 	// Apply client-side field selector filtering
 	if fieldSelector != "" {
-		result = filterByFieldSelector(result, fieldSelector)
+		if result, err = filterByFieldSelector(result, fieldSelector); err != nil {
+			return err
+		}
 	}
 
 	// Apply sorting if --sort-by is specified
 	if sortBy != "" {
-		result = sortResourcesByField(result, sortBy)
+		if result, err = sortResourcesByField(result, sortBy); err != nil {
+			return err
+		}
 	}
 
 	if err := printResourceList(result, rt, ns); err != nil {
 		return err
 	}
+	printNamespaceFailures(rt, failures)
 
-	// Display pagination info if there's more data
+	// Display pagination info if there's more data; manifests go to stderr
+	// to keep the YAML stream intact
 	if nextToken, ok := result["next_page_token"].(string); ok && nextToken != "" {
-		fmt.Printf("\n--- More results available. Use: --page-token=%s ---\n", nextToken)
+		w := os.Stdout
+		if outputFmt == "clean-yaml" {
+			w = os.Stderr
+		}
+		fmt.Fprintf(w, "\n--- More results available. Use: --page-token=%s ---\n", nextToken)
 	}
 
 	return nil
@@ -783,6 +868,13 @@ func deleteBySelector(ctx context.Context, client *runtime.Client, rt *ResourceT
 		return fmt.Errorf("invalid label selector: %s", labelSelector)
 	}
 
+	// Let the API narrow the list; every item is still checked against the
+	// full selector before it is deleted
+	params := map[string]string{}
+	if filter, _ := translateLabelSelector(labelSelector); filter != "" {
+		params["label_filter"] = filter
+	}
+
 	// Collect matching resources
 	var toDelete []struct {
 		name      string
@@ -791,7 +883,7 @@ func deleteBySelector(ctx context.Context, client *runtime.Client, rt *ResourceT
 
 	for _, ns := range namespaces {
 		path := rt.GetAPIPath(ns)
-		resp, err := client.Get(ctx, path, nil)
+		resp, err := client.Get(ctx, path, convertToURLValues(params))
 		if err != nil || !resp.IsSuccess() {
 			continue
 		}
@@ -890,7 +982,7 @@ func waitForResourceDeletion(client *runtime.Client, rt *ResourceType, ns, name
 }
 
 func runApply(cmd *cobra.Command, args []string) error {
-	if filename == "" {
+	if filename == "" && !hasProjectManifests() {
 		return fmt.Errorf("filename is required\n\nUsage: f5xcctl apply -f <filename>")
 	}
 	return applyFromFile(filename, false)
@@ -939,7 +1031,8 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return printDescribe(result, rt, resourceName)
+	details := fetchDescribeDetails(ctx, client, rt, ns, resourceName, result)
+	return printDescribe(result, rt, resourceName, details)
 }
 
 func runLabel(cmd *cobra.Command, args []string) error {
@@ -1146,7 +1239,7 @@ func deleteFromFile(filename string) error {
 }
 
 func applyFromFile(filename string, replaceOnly bool) error {
-	resources, err := readResourceFile(filename)
+	resources, err := readManifests(filename)
 	if err != nil {
 		return err
 	}
@@ -1167,6 +1260,45 @@ func applyFromFile(filename string, replaceOnly bool) error {
 	return nil
 }
 
+// hasProjectManifests reports whether the project file sets manifest
+// directories to use when no file is given.
+func hasProjectManifests() bool {
+	return activeProject != nil && len(activeProject.ManifestDirs) > 0
+}
+
+// readManifests reads the resources in filename, or in the manifest
+// directories of the project file when filename is empty.
+func readManifests(filename string) ([]map[string]interface{}, error) {
+	if filename != "" || !hasProjectManifests() {
+		return readResourceFile(filename)
+	}
+
+	var resources []map[string]interface{}
+	for _, dir := range activeProject.ManifestPaths() {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			switch filepath.Ext(path) {
+			case ".yaml", ".yml", ".json":
+			default:
+				return nil
+			}
+
+			docs, err := readResourceFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			resources = append(resources, docs...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifests in %s: %w", dir, err)
+		}
+	}
+	return resources, nil
+}
+
 func readResourceFile(filename string) ([]map[string]interface{}, error) {
 	var data []byte
 	var err error
@@ -1342,11 +1474,52 @@ func extractResourceInfo(resource map[string]interface{}) (kind, namespace, name
 	return
 }
 
-func listAllNamespaces(ctx context.Context, client *runtime.Client, rt *ResourceType) error {
-	// First get all namespaces
-	resp, err := client.Get(ctx, "/api/web/namespaces", nil)
+// listResources lists the resources of rt in namespace ns.
+func listResources(ctx context.Context, client *runtime.Client, rt *ResourceType, ns string, params map[string]string) (map[string]interface{}, error) {
+	resp, err := client.Get(ctx, rt.GetAPIPath(ns), convertToURLValues(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", rt.Plural, err)
+	}
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result, nil
+}
+
+// allNamespacesConcurrency bounds the namespaces listed at the same time by
+// 'get -A'.
+const allNamespacesConcurrency = 8
+
+// namespaceListTimeout bounds every request of 'get -A' on its own, so that
+// tenants with many namespaces do not run out of time.
+var namespaceListTimeout = 30 * time.Second
+
+// namespaceFailure records a namespace that could not be listed.
+type namespaceFailure struct {
+	Namespace string
+	Err       error
+}
+
+// listAllNamespaces lists the resources of rt in every namespace and merges
+// them into a single list, in namespace order. Namespaces that cannot be
+// listed, for example for lack of permissions, are returned as failures
+// rather than failing the whole list. Each request gets its own timeout of
+// namespaceListTimeout, so ctx should only carry cancellation.
+func listAllNamespaces(ctx context.Context, client *runtime.Client, rt *ResourceType, params map[string]string) (map[string]interface{}, []namespaceFailure, error) {
+	nsCtx, cancel := context.WithTimeout(ctx, namespaceListTimeout)
+	defer cancel()
+
+	resp, err := client.Get(nsCtx, "/api/web/namespaces", nil)
 	if err != nil {
-		return fmt.Errorf("failed to list namespaces: %w", err)
+		return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	if err := resp.Error(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
 	var nsResp struct {
@@ -1355,35 +1528,75 @@ func listAllNamespaces(ctx context.Context, client *runtime.Client, rt *Resource
 		} `json:"items"`
 	}
 	if err := resp.DecodeJSON(&nsResp); err != nil {
-		return err
+		return nil, nil, fmt.Errorf("failed to decode namespaces: %w", err)
 	}
 
-	fmt.Printf("NAMESPACE\tNAME\n")
-	for _, ns := range nsResp.Items {
-		path := rt.GetAPIPath(ns.Name)
-		resp, err := client.Get(ctx, path, nil)
-		if err != nil || !resp.IsSuccess() {
-			continue
+	// Page tokens belong to a single namespace
+	nsParams := make(map[string]string, len(params))
+	for k, v := range params {
+		if k != "page_token" {
+			nsParams[k] = v
 		}
+	}
 
-		var listResp struct {
-			Items []map[string]interface{} `json:"items"`
-		}
-		if err := resp.DecodeJSON(&listResp); err != nil {
+	lists := make([][]interface{}, len(nsResp.Items))
+	errs := make([]error, len(nsResp.Items))
+	sem := make(chan struct{}, allNamespacesConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range nsResp.Items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			listCtx, cancel := context.WithTimeout(ctx, namespaceListTimeout)
+			defer cancel()
+
+			result, err := listResources(listCtx, client, rt, item.Name, nsParams)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			items, _ := result["items"].([]interface{})
+			for _, it := range items {
+				// Items do not always carry their namespace
+				if m, ok := it.(map[string]interface{}); ok && m["namespace"] == nil && m["metadata"] == nil {
+					m["namespace"] = item.Name
+				}
+			}
+			lists[i] = items
+		}()
+	}
+	wg.Wait()
+
+	merged := []interface{}{}
+	var failures []namespaceFailure
+	for i, item := range nsResp.Items {
+		if errs[i] != nil {
+			failures = append(failures, namespaceFailure{Namespace: item.Name, Err: errs[i]})
 			continue
 		}
+		merged = append(merged, lists[i]...)
+	}
 
-		for _, item := range listResp.Items {
-			name := extractName(item)
-			fmt.Printf("%s\t%s\n", ns.Name, name)
-		}
+	return map[string]interface{}{"items": merged}, failures, nil
+}
+
+// printNamespaceFailures reports the namespaces 'get -A' could not list. It
+// writes to stderr so that machine-readable output stays intact.
+func printNamespaceFailures(rt *ResourceType, failures []namespaceFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nWarning: %s could not be listed in %d namespace(s):\n", rt.Plural, len(failures))
+	for _, f := range failures {
+		fmt.Fprintf(os.Stderr, "  %s: %v\n", f.Namespace, f.Err)
 	}
-	return nil
 }
 
-//nolint:unparam // rt kept for future resource-specific formatting
 func printResource(resource map[string]interface{}, rt *ResourceType) error {
-	// Handle advanced output formats (jsonpath, custom-columns, go-template)
+	// Handle advanced output formats (jsonpath, custom-columns, go-template, jq)
 	if isAdvancedOutputFormat(outputFmt) {
 		return printWithFormatter(resource)
 	}
@@ -1401,15 +1614,14 @@ func printResource(resource map[string]interface{}, rt *ResourceType) error {
 		name := extractName(resource)
 		fmt.Printf("%s/%s\n", rt.Name, name)
 		return nil
+	case "clean-yaml":
+		return printManifests([]interface{}{resource}, rt)
+	case output.StyleCSV, output.StyleTSV, output.StyleMarkdown:
+		return printResourceTable([]interface{}{resource}, rt, false)
+	case "wide":
+		return printResourceTable([]interface{}{resource}, rt, true)
 	default:
-		// Table format
-		name := extractName(resource)
-		age := extractAge(resource)
-		if !NoHeaders() {
-			fmt.Println("NAME\tAGE")
-		}
-		fmt.Printf("%s\t%s\n", name, age)
-		return nil
+		return printResourceTable([]interface{}{resource}, rt, false)
 	}
 }
 
@@ -1420,7 +1632,7 @@ func printResourceList(result map[string]interface{}, rt *ResourceType, ns strin
 		items = []interface{}{}
 	}
 
-	// Handle advanced output formats (jsonpath, custom-columns, go-template)
+	// Handle advanced output formats (jsonpath, custom-columns, go-template, jq)
 	if isAdvancedOutputFormat(outputFmt) {
 		return printWithFormatter(result)
 	}
@@ -1442,131 +1654,229 @@ func printResourceList(result map[string]interface{}, rt *ResourceType, ns strin
 			}
 		}
 		return nil
+	case "clean-yaml":
+		return printManifests(items, rt)
+	case output.StyleCSV, output.StyleTSV, output.StyleMarkdown:
+		return printResourceTable(items, rt, false)
 	case "wide":
-		if !NoHeaders() {
-			if rt.Namespaced {
-				fmt.Println("NAME\t\t\t\tNAMESPACE\tUID\t\t\t\t\t\tCREATED")
-			} else {
-				fmt.Println("NAME\t\t\t\tUID\t\t\t\t\t\tCREATED")
-			}
-		}
-		for _, item := range items {
-			if m, ok := item.(map[string]interface{}); ok {
-				name := extractName(m)
-				uid := extractUID(m)
-				created := extractCreated(m)
-				if rt.Namespaced {
-					itemNs := extractNamespace(m)
-					fmt.Printf("%-24s\t%s\t%s\t%s\n", name, itemNs, uid, created)
-				} else {
-					fmt.Printf("%-24s\t%s\t%s\n", name, uid, created)
-				}
-			}
-		}
-		return nil
+		return printResourceTable(items, rt, true)
 	default:
 		// Table output (default)
 		if len(items) == 0 {
-			output.Infof("No resources found in namespace %q", ns)
+			if ns == "" {
+				output.Infof("No resources found")
+			} else {
+				output.Infof("No resources found in namespace %q", ns)
+			}
 			return nil
 		}
+		return printResourceTable(items, rt, false)
+	}
+}
 
-		// Build header and determine columns
-		hasLabelColumns := len(labelColumns) > 0
-		if !NoHeaders() {
-			header := "NAME\tAGE"
-			if hasLabelColumns {
-				for _, lc := range labelColumns {
-					header += "\t" + strings.ToUpper(lc)
-				}
-			}
-			if showLabels {
-				header += "\tLABELS"
-			}
-			fmt.Println(header)
+// printManifests prints items as apply-ready manifests, separated as YAML
+// documents; see exportResource.
+func printManifests(items []interface{}, rt *ResourceType) error {
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
 		}
+		data, err := yaml.Marshal(exportResource(m, rt))
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", rt.Name, err)
+		}
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Print(string(data))
+	}
+	return nil
+}
 
-		for _, item := range items {
-			m, ok := item.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			name := extractName(m)
-			age := extractAge(m)
-			row := name + "\t" + age
-
-			// Add label columns (-L flag)
-			if hasLabelColumns {
-				labelsMap := extractLabelsMap(m)
-				for _, lc := range labelColumns {
-					val := labelsMap[lc]
-					if val == "" {
-						val = "<none>"
-					}
-					row += "\t" + val
-				}
-			}
-
-			// Add all labels (--show-labels flag)
-			if showLabels {
-				labels := extractLabels(m)
-				if labels == "" {
-					labels = "<none>"
-				}
-				row += "\t" + labels
-			}
+// printResourceTable prints items as a table with the printer columns of rt,
+// in CSV, TSV or Markdown if one of those is the output format.
+func printResourceTable(items []interface{}, rt *ResourceType, wide bool) error {
+	table := newResourceTable(rt, wide)
 
-			fmt.Println(row)
+	var rows [][]string
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, table.row(m))
 		}
-		return nil
 	}
+	if tabular := tabularFormatter(); tabular != nil {
+		return tabular.WriteTable(os.Stdout, table.headers(), rows)
+	}
+
+	headers := table.headers()
+	if NoHeaders() {
+		headers = nil
+	}
+	return output.PrintAligned(os.Stdout, headers, rows)
 }
 
-//nolint:unparam // error return kept for API consistency
-func printDescribe(resource map[string]interface{}, rt *ResourceType, name string) error {
-	fmt.Printf("Name:         %s\n", name)
-	fmt.Printf("Kind:         %s\n", rt.Kind)
+// resourceTable lays out the table output of a resource type. Wide output
+// adds the namespace, the priority columns, the UID and the creation time.
+// Lists across all namespaces always show the namespace. Columns are only
+// truncated to their width in aligned tables, not in CSV, TSV or Markdown.
+type resourceTable struct {
+	columns       []PrinterColumn
+	wide          bool
+	showNamespace bool
+}
 
-	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
-		if ns, ok := metadata["namespace"].(string); ok && ns != "" {
-			fmt.Printf("Namespace:    %s\n", ns)
-		}
-		if labels, ok := metadata["labels"].(map[string]interface{}); ok && len(labels) > 0 {
-			fmt.Println("Labels:")
-			for k, v := range labels {
-				fmt.Printf("              %s=%v\n", k, v)
+func newResourceTable(rt *ResourceType, wide bool) *resourceTable {
+	table := &resourceTable{
+		wide:          wide,
+		showNamespace: (wide || allNamespaces) && rt.Namespaced,
+	}
+	truncate := !output.IsTabularFormat(outputFmt)
+	for _, col := range rt.Columns {
+		if col.Priority == 0 || wide {
+			if !truncate {
+				col.Width = 0
 			}
+			table.columns = append(table.columns, col)
 		}
-		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok && len(annotations) > 0 {
-			fmt.Println("Annotations:")
-			for k, v := range annotations {
-				fmt.Printf("              %s=%v\n", k, v)
+	}
+	return table
+}
+
+// headers returns the column headers of the table.
+func (t *resourceTable) headers() []string {
+	headers := []string{"NAME"}
+	if t.showNamespace {
+		headers = append(headers, "NAMESPACE")
+	}
+	for _, col := range t.columns {
+		headers = append(headers, col.Header)
+	}
+	if t.wide {
+		headers = append(headers, "UID", "CREATED")
+	} else {
+		headers = append(headers, "AGE")
+	}
+	for _, lc := range labelColumns {
+		headers = append(headers, strings.ToUpper(lc))
+	}
+	if showLabels {
+		headers = append(headers, "LABELS")
+	}
+	return headers
+}
+
+// row returns the table row of a resource.
+func (t *resourceTable) row(m map[string]interface{}) []string {
+	view := columnView(m)
+
+	row := []string{extractName(m)}
+	if t.showNamespace {
+		row = append(row, extractNamespace(m))
+	}
+	for _, col := range t.columns {
+		row = append(row, columnValue(view, col))
+	}
+	if t.wide {
+		row = append(row, extractUID(m), extractCreated(m))
+	} else {
+		row = append(row, extractAge(m))
+	}
+
+	// Add label columns (-L flag)
+	if len(labelColumns) > 0 {
+		labelsMap := extractLabelsMap(m)
+		for _, lc := range labelColumns {
+			val := labelsMap[lc]
+			if val == "" {
+				val = "<none>"
 			}
+			row = append(row, val)
 		}
 	}
 
-	if sysMeta, ok := resource["system_metadata"].(map[string]interface{}); ok {
-		if uid, ok := sysMeta["uid"].(string); ok {
-			fmt.Printf("UID:          %s\n", uid)
-		}
-		if created, ok := sysMeta["creation_timestamp"].(string); ok {
-			fmt.Printf("Created:      %s\n", created)
+	// Add all labels (--show-labels flag)
+	if showLabels {
+		labels := extractLabels(m)
+		if labels == "" {
+			labels = "<none>"
 		}
+		row = append(row, labels)
 	}
 
-	fmt.Println("\nSpec:")
-	if spec, ok := resource["spec"].(map[string]interface{}); ok {
-		data, _ := yaml.Marshal(spec)
-		// Indent the spec
-		for _, line := range strings.Split(string(data), "\n") {
-			if line != "" {
-				fmt.Printf("  %s\n", line)
-			}
-		}
+	return row
+}
+
+// columnView returns the item that printer columns are evaluated against.
+// List responses carry the spec as get_spec (see wantsReportFields).
+func columnView(item map[string]interface{}) map[string]interface{} {
+	if _, ok := item["spec"]; ok {
+		return item
 	}
+	spec, ok := item["get_spec"]
+	if !ok {
+		return item
+	}
+	view := make(map[string]interface{}, len(item)+1)
+	for k, v := range item {
+		view[k] = v
+	}
+	view["spec"] = spec
+	return view
+}
 
-	return nil
+// wantsReportFields reports whether a list of rt needs the spec of its items,
+// which the API only includes when report_fields is requested.
+func wantsReportFields(rt *ResourceType) bool {
+	switch outputFmt {
+	case "clean-yaml":
+		return true
+	case "table", "wide", output.StyleCSV, output.StyleTSV, output.StyleMarkdown:
+		return len(rt.Columns) > 0
+	default:
+		return false
+	}
+}
+
+// columnValue renders the value of a printer column for item.
+func columnValue(item map[string]interface{}, col PrinterColumn) string {
+	var values []string
+	for _, path := range col.JSONPaths {
+		values = append(values, columnStrings(output.EvaluateJSONPath(item, path), path)...)
+	}
+
+	value := strings.Join(values, ",")
+	if value == "" {
+		return "<none>"
+	}
+	if runes := []rune(value); col.Width > 3 && len(runes) > col.Width {
+		value = string(runes[:col.Width-3]) + "..."
+	}
+	return value
+}
+
+// columnStrings flattens a value selected by path into column values.
+func columnStrings(value interface{}, path string) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, elem := range v {
+			values = append(values, columnStrings(elem, path)...)
+		}
+		return values
+	case map[string]interface{}:
+		// Objects are one-of choices; show which one is set
+		return []string{path[strings.LastIndex(path, ".")+1:]}
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
 }
 
 func extractName(resource map[string]interface{}) string {
@@ -1685,254 +1995,87 @@ func extractNamespace(resource map[string]interface{}) string {
 	return "default"
 }
 
-// watchResources implements the watch mode for the get command
-// It polls the API every 2 seconds and refreshes the display.
-func watchResources(client *runtime.Client, rt *ResourceType, ns, resourceName string) error {
-	// Set up signal handling for graceful exit
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	defer signal.Stop(sigChan)
-
-	// Channel to signal stop
-	stopChan := make(chan struct{})
-	go func() {
-		<-sigChan
-		close(stopChan)
-	}()
-
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	// Track previous state for change detection
-	var previousHash string
-
-	// Fetch and display function
-	fetchAndDisplay := func() (string, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		var result map[string]interface{}
-
-		if resourceName != "" {
-			// Watch a specific resource
-			path := rt.GetItemPath(ns, resourceName)
-			resp, err := client.Get(ctx, path, nil)
-			if err != nil {
-				return "", fmt.Errorf("failed to get %s %q: %w", rt.Name, resourceName, err)
-			}
-			if err := resp.Error(); err != nil {
-				return "", err
-			}
-			if err := resp.DecodeJSON(&result); err != nil {
-				return "", fmt.Errorf("failed to decode response: %w", err)
-			}
-		} else {
-			// Watch list of resources
-			if allNamespaces && rt.Namespaced {
-				// For all namespaces, we handle it differently
-				return watchAllNamespaces(ctx, client, rt)
-			}
-
-			path := rt.GetAPIPath(ns)
-			resp, err := client.Get(ctx, path, nil)
-			if err != nil {
-				return "", fmt.Errorf("failed to list %s: %w", rt.Plural, err)
-			}
-			if err := resp.Error(); err != nil {
-				return "", err
-			}
-			if err := resp.DecodeJSON(&result); err != nil {
-				return "", fmt.Errorf("failed to decode response: %w", err)
-			}
-		}
-
-		// Create hash of the result for change detection
-		jsonData, _ := json.Marshal(result)
-		currentHash := fmt.Sprintf("%x", jsonData)
-
-		return currentHash, nil
-	}
-
-	// Initial display
-	clearScreen()
-	printWatchHeader(rt, ns, resourceName)
-
-	hash, err := fetchAndDisplay()
-	if err != nil {
-		return err
+// convertToURLValues converts a map to url.Values.
+func convertToURLValues(params map[string]string) url.Values {
+	if params == nil {
+		return nil
 	}
-	previousHash = hash
-
-	if resourceName != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		path := rt.GetItemPath(ns, resourceName)
-		resp, _ := client.Get(ctx, path, nil)
-		var result map[string]interface{}
-		_ = resp.DecodeJSON(&result)
-		_ = printResource(result, rt)
-	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		path := rt.GetAPIPath(ns)
-		resp, _ := client.Get(ctx, path, nil)
-		var result map[string]interface{}
-		_ = resp.DecodeJSON(&result)
-		_ = printResourceList(result, rt, ns)
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
 	}
+	return values
+}
 
-	fmt.Printf("\n--- Last updated: %s (Ctrl+C to exit) ---\n", time.Now().Format("15:04:05"))
-
-	// Watch loop
-	for {
-		select {
-		case <-stopChan:
-			fmt.Println("\nWatch stopped.")
-			return nil
-		case <-ticker.C:
-			currentHash, err := fetchAndDisplay()
-			if err != nil {
-				output.Warningf("Error fetching resources: %v", err)
-				continue
-			}
-
-			// Only refresh display if something changed
-			if currentHash != previousHash {
-				clearScreen()
-				printWatchHeader(rt, ns, resourceName)
-
-				if resourceName != "" {
-					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-					path := rt.GetItemPath(ns, resourceName)
-					resp, _ := client.Get(ctx, path, nil)
-					var result map[string]interface{}
-					_ = resp.DecodeJSON(&result)
-					_ = printResource(result, rt)
-					cancel()
-				} else {
-					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-					path := rt.GetAPIPath(ns)
-					resp, _ := client.Get(ctx, path, nil)
-					var result map[string]interface{}
-					_ = resp.DecodeJSON(&result)
-					_ = printResourceList(result, rt, ns)
-					cancel()
-				}
+// Label keys and values the API accepts in label_filter expressions.
+var (
+	labelKeyPattern   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+)
 
-				previousHash = currentHash
-				fmt.Printf("\n--- Updated: %s (Ctrl+C to exit) ---\n", time.Now().Format("15:04:05"))
-			} else {
-				// Just update the timestamp
-				fmt.Printf("\r--- Last checked: %s (Ctrl+C to exit) ---", time.Now().Format("15:04:05"))
-			}
+// translateLabelSelector splits a kubectl-style label selector into a
+// label_filter expression the API evaluates server-side and the conditions
+// it cannot express, which have to be checked client-side. The API accepts
+// the Kubernetes selector syntax: key=value, key!=value, key in (a, b),
+// key notin (a, b), key and !key, but only well-formed keys and values.
+func translateLabelSelector(selector string) (string, []labelCondition) {
+	var server []string
+	var remainder []labelCondition
+	for _, cond := range parseLabelSelector(selector) {
+		if filter, ok := labelFilterExpression(cond); ok {
+			server = append(server, filter)
+		} else {
+			remainder = append(remainder, cond)
 		}
 	}
+	return strings.Join(server, ","), remainder
 }
 
-// watchAllNamespaces handles watch mode for all namespaces.
-func watchAllNamespaces(ctx context.Context, client *runtime.Client, rt *ResourceType) (string, error) {
-	// First get all namespaces
-	resp, err := client.Get(ctx, "/api/web/namespaces", nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to list namespaces: %w", err)
-	}
-
-	var nsResp struct {
-		Items []struct {
-			Name string `json:"name"`
-		} `json:"items"`
-	}
-	if err := resp.DecodeJSON(&nsResp); err != nil {
-		return "", err
+// labelFilterExpression renders a condition in label_filter syntax. It
+// reports false for conditions the API cannot evaluate.
+func labelFilterExpression(cond labelCondition) (string, bool) {
+	if !labelKeyPattern.MatchString(cond.key) {
+		return "", false
 	}
-
-	var allItems []map[string]interface{}
-	for _, ns := range nsResp.Items {
-		path := rt.GetAPIPath(ns.Name)
-		resp, err := client.Get(ctx, path, nil)
-		if err != nil || !resp.IsSuccess() {
-			continue
+	for _, v := range cond.values {
+		if !labelValuePattern.MatchString(v) {
+			return "", false
 		}
+	}
 
-		var listResp struct {
-			Items []map[string]interface{} `json:"items"`
+	switch cond.operator {
+	case "=", "==":
+		if len(cond.values) != 1 {
+			return "", false
 		}
-		if err := resp.DecodeJSON(&listResp); err != nil {
-			continue
+		return cond.key + "=" + cond.values[0], true
+	case "!=":
+		if len(cond.values) != 1 {
+			return "", false
 		}
-
-		for _, item := range listResp.Items {
-			// Add namespace info
-			item["_namespace"] = ns.Name
-			allItems = append(allItems, item)
+		return cond.key + "!=" + cond.values[0], true
+	case "in", "notin":
+		if len(cond.values) == 0 {
+			return "", false
 		}
+		return fmt.Sprintf("%s %s (%s)", cond.key, cond.operator, strings.Join(cond.values, ", ")), true
+	case "exists":
+		return cond.key, true
+	case "notexists":
+		return "!" + cond.key, true
+	default:
+		return "", false
 	}
-
-	// Create hash for change detection
-	jsonData, _ := json.Marshal(allItems)
-	return fmt.Sprintf("%x", jsonData), nil
-}
-
-// clearScreen clears the terminal screen.
-func clearScreen() {
-	fmt.Print("\033[H\033[2J")
-}
-
-// printWatchHeader prints the header for watch mode.
-func printWatchHeader(rt *ResourceType, ns, resourceName string) {
-	if resourceName != "" {
-		fmt.Printf("Watching %s/%s", rt.Name, resourceName)
-	} else {
-		fmt.Printf("Watching %s", rt.Plural)
-	}
-	if ns != "" && ns != "default" {
-		fmt.Printf(" in namespace %q", ns)
-	} else if allNamespaces {
-		fmt.Print(" in all namespaces")
-	}
-	fmt.Println()
-	fmt.Println()
-}
-
-// convertToURLValues converts a map to url.Values.
-func convertToURLValues(params map[string]string) url.Values {
-	if params == nil {
-		return nil
-	}
-	values := url.Values{}
-	for k, v := range params {
-		values.Set(k, v)
-	}
-	return values
 }
 
-// convertLabelSelector converts kubectl-style label selectors to F5XC format
-// kubectl: "env=prod,team=platform" or "env in (prod,staging)"
-// F5XC: Uses label_filter parameter format.
-func convertLabelSelector(selector string) string {
-	// F5XC API may accept different formats; for now, pass through
-	// The API typically expects: key=value format
-	return selector
-}
-
-// filterByLabelSelector filters resources by label selector (client-side fallback)
-// Supports:
-//   - equality: key=value, key==value
-//   - inequality: key!=value
-//   - set-based: key in (val1,val2), key notin (val1,val2), key, !key
-func filterByLabelSelector(result map[string]interface{}, selector string) map[string]interface{} {
+// filterByLabelConditions filters resources by label conditions (client-side).
+func filterByLabelConditions(result map[string]interface{}, conditions []labelCondition) map[string]interface{} {
 	items, ok := result["items"].([]interface{})
-	if !ok {
-		return result
-	}
-
-	// Parse selector into conditions
-	conditions := parseLabelSelector(selector)
-	if len(conditions) == 0 {
+	if !ok || len(conditions) == 0 {
 		return result
 	}
 
-	var filtered []interface{}
+	filtered := []interface{}{}
 	for _, item := range items {
 		if m, ok := item.(map[string]interface{}); ok {
 			labels := extractLabelsMap(m)
@@ -2151,76 +2294,138 @@ func completeResourceTypes(cmd *cobra.Command, args []string, toComplete string)
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
-// filterByFieldSelector filters resources by field selector (client-side)
-// Supports paths like metadata.name=value, metadata.namespace=value, spec.field=value.
-func filterByFieldSelector(result map[string]interface{}, selector string) map[string]interface{} {
-	items, ok := result["items"].([]interface{})
-	if !ok {
-		return result
+// filterByFieldSelector filters resources by field selector (client-side).
+// Fields are JSONPath expressions over the resource, including its spec
+// (e.g., spec.port>1024 or metadata.name in (a,b)); see parseFieldSelector.
+func filterByFieldSelector(result map[string]interface{}, selector string) (map[string]interface{}, error) {
+	conditions, err := parseFieldSelector(selector)
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse field selector into conditions
-	conditions := parseFieldSelector(selector)
-	if len(conditions) == 0 {
-		return result
+	items, ok := result["items"].([]interface{})
+	if !ok || len(conditions) == 0 {
+		return result, nil
 	}
 
-	var filtered []interface{}
+	filtered := []interface{}{}
 	for _, item := range items {
 		if m, ok := item.(map[string]interface{}); ok {
-			if matchesFieldConditions(m, conditions) {
+			if matchesFieldConditions(columnView(m), conditions) {
 				filtered = append(filtered, item)
 			}
 		}
 	}
 
 	result["items"] = filtered
-	return result
+	return result, nil
 }
 
 // fieldCondition represents a parsed field selector condition.
 type fieldCondition struct {
-	path     string // e.g., "metadata.name"
-	operator string // "=" or "!="
-	value    string
-}
-
-// parseFieldSelector parses a kubectl-style field selector string
-// Format: field1=value1,field2!=value2.
-func parseFieldSelector(selector string) []fieldCondition {
+	path     *output.JSONPath
+	operator string // "=", "!=", "in", "notin", "=~", "!~", "<", "<=", ">", ">="
+	values   []string
+	pattern  *regexp.Regexp // "=~" and "!~"
+	number   float64        // "<", "<=", ">" and ">="
+}
+
+// fieldOperators are the field selector operators, in the order they are
+// looked for.
+var fieldOperators = []string{" notin ", " in ", "=~", "!~", "!=", "==", "<=", ">=", "=", "<", ">"}
+
+// parseFieldSelector parses a field selector: comma-separated conditions of
+// the form <field><operator><value>, where the operators are
+//
+//	=, ==, !=              equality
+//	in (a,b), notin (a,b)  set membership
+//	=~, !~                 regular expression match
+//	<, <=, >, >=           numeric comparison
+//
+// When a field selects several values, a condition holds if any of them
+// matches; for !=, notin and !~ if none of them matches the positive form.
+func parseFieldSelector(selector string) ([]fieldCondition, error) {
 	var conditions []fieldCondition
 
-	parts := strings.Split(selector, ",")
-	for _, part := range parts {
+	for _, part := range splitLabelSelector(selector) {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
 
-		cond := fieldCondition{}
+		field, op, value, ok := cutFieldOperator(part)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid field selector %q: expected <field><operator><value>", part)
+		}
 
-		if strings.Contains(part, "!=") {
-			kv := strings.SplitN(part, "!=", 2)
-			cond.path = strings.TrimSpace(kv[0])
-			cond.operator = "!="
-			if len(kv) > 1 {
-				cond.value = strings.TrimSpace(kv[1])
-			}
-		} else if strings.Contains(part, "=") {
-			kv := strings.SplitN(part, "=", 2)
-			cond.path = strings.TrimSpace(kv[0])
+		path, err := output.CompileJSONPathExpression(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector %q: %w", part, err)
+		}
+		cond := fieldCondition{path: path, operator: op}
+
+		switch op {
+		case "==":
 			cond.operator = "="
-			if len(kv) > 1 {
-				cond.value = strings.TrimSpace(kv[1])
+			cond.values = []string{value}
+		case "=", "!=":
+			cond.values = []string{value}
+		case "in", "notin":
+			if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+				return nil, fmt.Errorf("invalid field selector %q: expected a list of values in parentheses", part)
+			}
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				cond.values = append(cond.values, strings.TrimSpace(v))
+			}
+		case "=~", "!~":
+			if cond.pattern, err = regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("invalid field selector %q: %w", part, err)
+			}
+		default:
+			if cond.number, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("invalid field selector %q: %s needs a number", part, op)
 			}
 		}
 
-		if cond.path != "" {
-			conditions = append(conditions, cond)
-		}
+		conditions = append(conditions, cond)
 	}
 
-	return conditions
+	return conditions, nil
+}
+
+// cutFieldOperator splits a field selector condition at its operator,
+// ignoring operators inside brackets or quotes of the field expression.
+func cutFieldOperator(part string) (field, op, value string, ok bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(part); i++ {
+		c := part[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+			continue
+		case c == '[' || c == '(':
+			depth++
+			continue
+		case c == ']' || c == ')':
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, candidate := range fieldOperators {
+			if strings.HasPrefix(part[i:], candidate) {
+				return strings.TrimSpace(part[:i]), strings.TrimSpace(candidate), strings.TrimSpace(part[i+len(candidate):]), true
+			}
+		}
+	}
+	return "", "", "", false
 }
 
 // matchesFieldConditions checks if a resource matches all field conditions.
@@ -2235,72 +2440,79 @@ func matchesFieldConditions(resource map[string]interface{}, conditions []fieldC
 
 // matchesFieldCondition checks if a resource matches a single field condition.
 func matchesFieldCondition(resource map[string]interface{}, cond fieldCondition) bool {
-	// Get the field value using the path
-	value := getFieldValue(resource, cond.path)
-	valueStr := fmt.Sprintf("%v", value)
+	values := cond.path.FindResults(resource)
 
-	switch cond.operator {
-	case "=":
-		return value != nil && valueStr == cond.value
-	case "!=":
-		return value == nil || valueStr != cond.value
-	default:
-		return true
+	anyMatch := func(match func(string) bool) bool {
+		for _, v := range values {
+			if match(output.FormatJSONPathValue(v)) {
+				return true
+			}
+		}
+		return false
 	}
-}
-
-// getFieldValue retrieves a value from a nested map using a dot-separated path.
-func getFieldValue(resource map[string]interface{}, path string) interface{} {
-	parts := strings.Split(path, ".")
-	var current interface{} = resource
-
-	for _, part := range parts {
-		if part == "" {
-			continue
+	inValues := func(s string) bool {
+		for _, v := range cond.values {
+			if s == v {
+				return true
+			}
 		}
-
-		switch v := current.(type) {
-		case map[string]interface{}:
-			current = v[part]
+		return false
+	}
+	compare := func(s string) bool {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return false
+		}
+		switch cond.operator {
+		case "<":
+			return n < cond.number
+		case "<=":
+			return n <= cond.number
+		case ">":
+			return n > cond.number
 		default:
-			return nil
+			return n >= cond.number
 		}
 	}
 
-	return current
+	switch cond.operator {
+	case "=", "in":
+		return anyMatch(inValues)
+	case "!=", "notin":
+		return !anyMatch(inValues)
+	case "=~":
+		return anyMatch(cond.pattern.MatchString)
+	case "!~":
+		return !anyMatch(cond.pattern.MatchString)
+	default:
+		return anyMatch(compare)
+	}
 }
 
 // ============================================================================
 // Sorting Helpers
 // ============================================================================
 
-// sortResourcesByField sorts resources by a JSONPath-like field expression.
-// Supports simple paths like ".metadata.name", ".metadata.creationTimestamp", ".spec.field".
-func sortResourcesByField(result map[string]interface{}, fieldPath string) map[string]interface{} {
+// sortResourcesByField sorts resources by the value of a JSONPath
+// expression, such as ".metadata.name" or "{.spec.domains[0]}".
+func sortResourcesByField(result map[string]interface{}, fieldPath string) (map[string]interface{}, error) {
+	path, err := output.CompileJSONPathExpression(fieldPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sort-by: %w", err)
+	}
+
 	items, ok := result["items"].([]interface{})
 	if !ok || len(items) == 0 {
-		return result
+		return result, nil
 	}
 
-	// Normalize the path (remove leading dot if present)
-	path := strings.TrimPrefix(fieldPath, ".")
-
 	// Sort the items
 	sort.SliceStable(items, func(i, j int) bool {
-		itemI, okI := items[i].(map[string]interface{})
-		itemJ, okJ := items[j].(map[string]interface{})
-		if !okI || !okJ {
-			return false
-		}
-
-		valI := getFieldValue(itemI, path)
-		valJ := getFieldValue(itemJ, path)
-
-		return compareValues(valI, valJ) < 0
+		return compareValues(path.Evaluate(items[i]), path.Evaluate(items[j])) < 0
 	})
 
 	result["items"] = items
-	return result
+	return result, nil
 }
 
 // compareValues compares two values for sorting.
@@ -2381,9 +2593,14 @@ func isAdvancedOutputFormat(format string) bool {
 	return strings.HasPrefix(format, "jsonpath=") ||
 		strings.HasPrefix(format, "custom-columns=") ||
 		strings.HasPrefix(format, "go-template=") ||
+		strings.HasPrefix(format, "jq=") ||
+		strings.HasPrefix(format, "csv=") ||
+		strings.HasPrefix(format, "tsv=") ||
+		strings.HasPrefix(format, "markdown=") ||
 		format == "jsonpath" ||
 		format == "custom-columns" ||
-		format == "go-template"
+		format == "go-template" ||
+		format == "jq"
 }
 
 // printWithFormatter uses the output formatter to print data.
@@ -2401,5 +2618,35 @@ func printWithFormatter(data interface{}) error {
 	}
 
 	formatter := output.NewFormatter(format)
+	switch f := formatter.(type) {
+	case *output.JQFormatter:
+		f.RawOutput = RawOutput()
+	case *output.TabularFormatter:
+		f.NoHeaders = NoHeaders()
+	}
 	return formatter.Format(os.Stdout, data)
 }
+
+// tabularFormatter returns the formatter of the output format if it is CSV,
+// TSV or Markdown, and nil otherwise.
+func tabularFormatter() *output.TabularFormatter {
+	if !output.IsTabularFormat(outputFmt) {
+		return nil
+	}
+	f, ok := output.NewFormatter(outputFmt).(*output.TabularFormatter)
+	if !ok {
+		return nil
+	}
+	f.NoHeaders = NoHeaders()
+	return f
+}
+
+// printTable prints the rows of a list command (a slice of row structs) as
+// an aligned table, or in CSV, TSV or Markdown if one of those is the output
+// format.
+func printTable(rows interface{}) error {
+	if tabular := tabularFormatter(); tabular != nil {
+		return tabular.Format(os.Stdout, rows)
+	}
+	return output.Print("table", rows)
+}
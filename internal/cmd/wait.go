@@ -40,7 +40,10 @@ Examples:
   f5xcctl wait httplb my-lb --for=condition=Ready -n production
 
   # Wait using JSONPath
-  f5xcctl wait httplb my-lb --for=jsonpath='{.status.state}'=active -n production`,
+  f5xcctl wait httplb my-lb --for=jsonpath='{.status.state}'=active -n production
+
+  # Wait using a JSONPath filter; the expression must select a single value
+  f5xcctl wait site my-site --for=jsonpath='{.status[?(@.type=="Ready")].status}'=True`,
 	Args: cobra.ExactArgs(2),
 	RunE: runWait,
 }
@@ -186,14 +189,14 @@ func waitForCondition(client *runtime.Client, rt *ResourceType, ns, name, condit
 
 // waitForJSONPath waits for a JSONPath expression to equal a specific value.
 func waitForJSONPath(client *runtime.Client, rt *ResourceType, ns, name, expr string, timeout time.Duration) error {
-	// Parse expr: {.status.state}=active
-	parts := strings.SplitN(expr, "=", 2)
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid jsonpath expression: %s\n\nExpected format: jsonpath='{.path.to.field}'=value", expr)
+	jsonPath, expectedValue, err := splitWaitJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	compiled, err := output.CompileJSONPathExpression(jsonPath)
+	if err != nil {
+		return err
 	}
-
-	jsonPath := strings.Trim(parts[0], "'{}")
-	expectedValue := parts[1]
 
 	output.Infof("Waiting for %s/%s %s=%s...", rt.Name, name, jsonPath, expectedValue)
 
@@ -227,9 +230,11 @@ func waitForJSONPath(client *runtime.Client, rt *ResourceType, ns, name, expr st
 			continue
 		}
 
-		// Simple JSONPath evaluation
-		value := evaluateSimpleJSONPath(result, jsonPath)
-		if fmt.Sprintf("%v", value) == expectedValue {
+		values := compiled.FindResults(result)
+		if len(values) > 1 {
+			return fmt.Errorf("jsonpath %s matches %d values, expected one", jsonPath, len(values))
+		}
+		if len(values) == 1 && output.FormatJSONPathValue(values[0]) == expectedValue {
 			output.Successf("%s/%s %s=%s", rt.Name, name, jsonPath, expectedValue)
 			return nil
 		}
@@ -238,27 +243,23 @@ func waitForJSONPath(client *runtime.Client, rt *ResourceType, ns, name, expr st
 	}
 }
 
-// evaluateSimpleJSONPath evaluates a simple JSONPath expression
-// Supports paths like ".status.state" or ".metadata.name".
-func evaluateSimpleJSONPath(data map[string]interface{}, path string) interface{} {
-	// Remove leading dot if present
-	path = strings.TrimPrefix(path, ".")
-
-	parts := strings.Split(path, ".")
-	var current interface{} = data
+// splitWaitJSONPath splits the argument of --for=jsonpath= into the
+// expression and the expected value: {.status.state}=active. Without braces,
+// the expression ends at the first "=".
+func splitWaitJSONPath(expr string) (string, string, error) {
+	invalid := fmt.Errorf("invalid jsonpath condition: %s\n\nExpected format: jsonpath='{.path.to.field}'=value", expr)
 
-	for _, part := range parts {
-		if part == "" {
-			continue
-		}
-
-		switch v := current.(type) {
-		case map[string]interface{}:
-			current = v[part]
-		default:
-			return nil
+	if strings.HasPrefix(expr, "{") {
+		end := strings.LastIndex(expr, "}=")
+		if end < 0 {
+			return "", "", invalid
 		}
+		return expr[:end+1], expr[end+2:], nil
 	}
 
-	return current
+	jsonPath, value, ok := strings.Cut(expr, "=")
+	if !ok || jsonPath == "" {
+		return "", "", invalid
+	}
+	return jsonPath, value, nil
 }
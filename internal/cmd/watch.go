@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/f5/f5xcctl/internal/output"
+	"github.com/f5/f5xcctl/internal/runtime"
+)
+
+// Watch event types, as in Kubernetes watch streams.
+const (
+	watchAdded    = "ADDED"
+	watchModified = "MODIFIED"
+	watchDeleted  = "DELETED"
+)
+
+// maxWatchBackoff caps the delay between retries after failed refreshes.
+const maxWatchBackoff = time.Minute
+
+// defaultWatchInterval is the default polling interval of --watch.
+const defaultWatchInterval = 2 * time.Second
+
+var (
+	watchOnly         bool
+	watchInterval     time.Duration
+	outputWatchEvents bool
+)
+
+// watchEvent is a change between two snapshots of the watched resources.
+type watchEvent struct {
+	Type   string                 `json:"type"`
+	Object map[string]interface{} `json:"object"`
+}
+
+// watchSnapshot is the state of the watched resources at one point in time,
+// keyed by namespace/name.
+type watchSnapshot struct {
+	keys    []string
+	objects map[string]map[string]interface{}
+}
+
+// newWatchSnapshot builds a snapshot from a list of items, keeping their
+// order.
+func newWatchSnapshot(items []interface{}) *watchSnapshot {
+	snap := &watchSnapshot{objects: make(map[string]map[string]interface{})}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := extractNamespace(m) + "/" + extractName(m)
+		if _, dup := snap.objects[key]; !dup {
+			snap.keys = append(snap.keys, key)
+		}
+		snap.objects[key] = m
+	}
+	return snap
+}
+
+// diffSnapshots returns the events that turn prev into cur: ADDED and
+// MODIFIED in the order of cur, followed by DELETED in key order.
+func diffSnapshots(prev, cur *watchSnapshot) []watchEvent {
+	var events []watchEvent
+	for _, key := range cur.keys {
+		obj := cur.objects[key]
+		old, existed := prev.objects[key]
+		switch {
+		case !existed:
+			events = append(events, watchEvent{Type: watchAdded, Object: obj})
+		case resourceVersion(old) != resourceVersion(obj):
+			events = append(events, watchEvent{Type: watchModified, Object: obj})
+		}
+	}
+
+	var deleted []string
+	for key := range prev.objects {
+		if _, ok := cur.objects[key]; !ok {
+			deleted = append(deleted, key)
+		}
+	}
+	sort.Strings(deleted)
+	for _, key := range deleted {
+		events = append(events, watchEvent{Type: watchDeleted, Object: prev.objects[key]})
+	}
+	return events
+}
+
+// resourceVersion identifies the revision of a resource. The API does not
+// always report a resource version, so the modification timestamp and, as
+// a last resort, the content itself stand in for it.
+func resourceVersion(obj map[string]interface{}) string {
+	for _, path := range []string{".system_metadata.resource_version", ".metadata.resource_version", ".resource_version", ".system_metadata.modification_timestamp"} {
+		if v, ok := output.EvaluateJSONPath(obj, path).(string); ok && v != "" {
+			return v
+		}
+	}
+	data, _ := json.Marshal(obj)
+	return string(data)
+}
+
+// resourceWatcher polls the watched resources and reports changes.
+type resourceWatcher struct {
+	client       *runtime.Client
+	rt           *ResourceType
+	ns           string
+	resourceName string
+	params       map[string]string
+	// labelConditions are checked client-side, see translateLabelSelector
+	labelConditions []labelCondition
+}
+
+// snapshot fetches the current state of the watched resources.
+func (w *resourceWatcher) snapshot(ctx context.Context) (*watchSnapshot, error) {
+	// Lists across all namespaces time out per namespace
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if w.resourceName != "" {
+		resp, err := w.client.Get(reqCtx, w.rt.GetItemPath(w.ns, w.resourceName), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %q: %w", w.rt.Name, w.resourceName, err)
+		}
+		if err := resp.Error(); err != nil {
+			// A deleted resource is an empty snapshot, not an error
+			var apiErr *runtime.APIError
+			if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+				return newWatchSnapshot(nil), nil
+			}
+			return nil, err
+		}
+		var result map[string]interface{}
+		if err := resp.DecodeJSON(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return newWatchSnapshot([]interface{}{result}), nil
+	}
+
+	var (
+		result   map[string]interface{}
+		failures []namespaceFailure
+		err      error
+	)
+	if allNamespaces && w.rt.Namespaced {
+		result, failures, err = listAllNamespaces(ctx, w.client, w.rt, w.params)
+	} else {
+		result, err = listResources(reqCtx, w.client, w.rt, w.ns, w.params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Namespaces that cannot be listed would show up as deletions
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("failed to list %s in namespace %s: %w", w.rt.Plural, failures[0].Namespace, failures[0].Err)
+	}
+
+	result = filterByLabelConditions(result, w.labelConditions)
+	if fieldSelector != "" {
+		if result, err = filterByFieldSelector(result, fieldSelector); err != nil {
+			return nil, err
+		}
+	}
+	if sortBy != "" {
+		if result, err = sortResourcesByField(result, sortBy); err != nil {
+			return nil, err
+		}
+	}
+	items, _ := result["items"].([]interface{})
+	return newWatchSnapshot(items), nil
+}
+
+// run polls until ctx is done, passing the changes of every refresh to
+// emit. The initial state is reported as ADDED events unless --watch-only
+// is set. Failed refreshes are retried with exponential backoff.
+func (w *resourceWatcher) run(ctx context.Context, interval time.Duration, emit func([]watchEvent) error) error {
+	prev, err := w.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if !watchOnly {
+		if err := emit(diffSnapshots(newWatchSnapshot(nil), prev)); err != nil {
+			return err
+		}
+	}
+
+	delay := interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		cur, err := w.snapshot(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			delay = min(delay*2, maxWatchBackoff)
+			fmt.Fprintf(os.Stderr, "Warning: failed to refresh %s (retrying in %s): %v\n", w.rt.Plural, delay, err)
+		default:
+			delay = interval
+			if events := diffSnapshots(prev, cur); len(events) > 0 {
+				if err := emit(events); err != nil {
+					return err
+				}
+			}
+			prev = cur
+		}
+		timer.Reset(delay)
+	}
+}
+
+// watchResources implements the watch mode of the get command. Changes are
+// streamed as they are detected, so the output can be piped: -o json
+// prints one event per line.
+func watchResources(client *runtime.Client, rt *ResourceType, ns, resourceName string, params map[string]string, labelConditions []labelCondition) error {
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	// Changes are detected through the system metadata, which the API only
+	// reports along with the spec
+	watchParams := map[string]string{"report_fields": ""}
+	for k, v := range params {
+		if k != "page_token" {
+			watchParams[k] = v
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := &resourceWatcher{
+		client:          client,
+		rt:              rt,
+		ns:              ns,
+		resourceName:    resourceName,
+		params:          watchParams,
+		labelConditions: labelConditions,
+	}
+	printer := newWatchPrinter(rt)
+	return watcher.run(ctx, watchInterval, printer.print)
+}
+
+// watchPrinter prints watch events in the selected output format.
+type watchPrinter struct {
+	rt            *ResourceType
+	table         *resourceTable
+	headerPrinted bool
+}
+
+func newWatchPrinter(rt *ResourceType) *watchPrinter {
+	return &watchPrinter{rt: rt, table: newResourceTable(rt, outputFmt == "wide")}
+}
+
+// print prints a batch of events.
+func (p *watchPrinter) print(events []watchEvent) error {
+	if isAdvancedOutputFormat(outputFmt) {
+		for _, event := range events {
+			if err := printWithFormatter(p.eventValue(event)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch outputFmt {
+	case "json":
+		for _, event := range events {
+			data, err := json.Marshal(p.eventValue(event))
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+			fmt.Println(string(data))
+		}
+	case "yaml", "clean-yaml":
+		for _, event := range events {
+			data, err := yaml.Marshal(p.eventValue(event))
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+			fmt.Printf("---\n%s", data)
+		}
+	case "name":
+		for _, event := range events {
+			name := fmt.Sprintf("%s/%s", p.rt.Name, extractName(event.Object))
+			if outputWatchEvents {
+				name = event.Type + " " + name
+			}
+			fmt.Println(name)
+		}
+	default:
+		var headers []string
+		if !p.headerPrinted {
+			headers = p.table.headers()
+			if outputWatchEvents {
+				headers = append([]string{"EVENT"}, headers...)
+			}
+			p.headerPrinted = true
+		}
+		rows := make([][]string, 0, len(events))
+		for _, event := range events {
+			row := p.table.row(event.Object)
+			if outputWatchEvents {
+				row = append([]string{event.Type}, row...)
+			}
+			rows = append(rows, row)
+		}
+
+		// CSV, TSV and Markdown continue the table with every batch
+		if tabular := tabularFormatter(); tabular != nil {
+			return tabular.WriteTable(os.Stdout, headers, rows)
+		}
+
+		if NoHeaders() {
+			headers = nil
+		}
+		return output.PrintAligned(os.Stdout, headers, rows)
+	}
+	return nil
+}
+
+// eventValue returns what is printed for an event in structured formats:
+// the object itself, or the whole event with --output-watch-events. With
+// -o clean-yaml the object is the manifest of the resource.
+func (p *watchPrinter) eventValue(event watchEvent) interface{} {
+	if outputFmt == "clean-yaml" {
+		event.Object = exportResource(event.Object, p.rt)
+	}
+	if outputWatchEvents {
+		return event
+	}
+	return event.Object
+}
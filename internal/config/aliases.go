@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// aliasArgPattern matches positional references ($1, $2, ...) in an alias.
+var aliasArgPattern = regexp.MustCompile(`\$([1-9][0-9]*)`)
+
+// ExpandAlias returns the command line the alias name expands to. $1, $2,
+// ... are replaced by the positional arguments in args and a "$@" word by
+// all of them; arguments that are not referenced are appended.
+func (c *Config) ExpandAlias(name string, args []string) ([]string, error) {
+	line, ok := c.Aliases[name]
+	if !ok {
+		return nil, fmt.Errorf("alias %q not found", name)
+	}
+
+	words, err := SplitCommandLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %q: %w", name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("alias %q is empty", name)
+	}
+
+	required := 0
+	for _, ref := range aliasArgPattern.FindAllStringSubmatch(line, -1) {
+		n, _ := strconv.Atoi(ref[1])
+		required = max(required, n)
+	}
+	if required > len(args) {
+		return nil, fmt.Errorf("alias %q expects at least %d argument(s): %s", name, required, line)
+	}
+
+	used := make([]bool, len(args))
+	var expanded []string
+	for _, word := range words {
+		if word == "$@" {
+			expanded = append(expanded, args...)
+			for i := range used {
+				used[i] = true
+			}
+			continue
+		}
+
+		word = aliasArgPattern.ReplaceAllStringFunc(word, func(ref string) string {
+			n, _ := strconv.Atoi(ref[1:])
+			used[n-1] = true
+			return args[n-1]
+		})
+		expanded = append(expanded, word)
+	}
+
+	for i, arg := range args {
+		if !used[i] {
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+// DefaultFlags returns the default flags configured for a command path such
+// as "get" or "config view".
+func (c *Config) DefaultFlags(commandPath string) ([]string, error) {
+	line, ok := c.Defaults[commandPath]
+	if !ok {
+		return nil, nil
+	}
+	words, err := SplitCommandLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid defaults for %q: %w", commandPath, err)
+	}
+	return words, nil
+}
+
+// SplitCommandLine splits s into words the way a POSIX shell does, honoring
+// single quotes, double quotes and backslash escapes. Nothing is expanded.
+func SplitCommandLine(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
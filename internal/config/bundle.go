@@ -0,0 +1,343 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleKind identifies profile bundles written by ExportProfile.
+const BundleKind = "ProfileBundle"
+
+// Key derivation parameters for encrypted bundle secrets.
+const (
+	bundleCipher     = "aes-256-gcm"
+	bundleKDF        = "pbkdf2-sha256"
+	bundleIterations = 600000
+	// Bundles with fewer iterations are too easy to brute-force, bundles
+	// with more would take minutes to decrypt
+	minBundleIterations = 100000
+	maxBundleIterations = 10000000
+)
+
+// ErrWrongPassphrase is returned when bundle secrets cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted bundle")
+
+// Bundle is a portable export of a single profile. Certificate file paths
+// are reduced to their base names; the files themselves, like all other
+// secrets, are only included in the encrypted Secrets section.
+type Bundle struct {
+	APIVersion   string            `yaml:"apiVersion"`
+	Kind         string            `yaml:"kind"`
+	Profile      string            `yaml:"profile"`
+	Tenant       Tenant            `yaml:"tenant"`
+	User         User              `yaml:"user"`
+	Namespace    string            `yaml:"namespace,omitempty"`
+	OutputFormat string            `yaml:"output-format,omitempty"`
+	Protected    bool              `yaml:"protected,omitempty"`
+	ReadOnly     bool              `yaml:"read-only,omitempty"`
+	Secrets      *EncryptedSecrets `yaml:"secrets,omitempty"`
+}
+
+// EncryptedSecrets holds bundleSecrets encrypted with a passphrase.
+type EncryptedSecrets struct {
+	Cipher     string `yaml:"cipher"`
+	KDF        string `yaml:"kdf"`
+	Iterations int    `yaml:"iterations"`
+	Salt       string `yaml:"salt"`
+	Nonce      string `yaml:"nonce"`
+	Data       string `yaml:"data"`
+}
+
+// bundleSecrets is the plaintext of EncryptedSecrets.
+type bundleSecrets struct {
+	Credentials ProfileCredentials `yaml:"credentials"`
+	// Files maps certificate file base names to their contents.
+	Files map[string][]byte `yaml:"files,omitempty"`
+}
+
+// ImportOptions control how a bundle is merged into a configuration.
+type ImportOptions struct {
+	// Name imports the profile under another name.
+	Name string
+	// Overwrite replaces an existing profile and certificate files.
+	Overwrite bool
+	// CertDir is where certificate files are expected or written.
+	CertDir string
+	// Passphrase decrypts the bundle secrets; without it they are skipped.
+	Passphrase string
+}
+
+// ImportResult describes what ImportBundle changed.
+type ImportResult struct {
+	Profile string
+	// Files lists the certificate files written from the bundle.
+	Files []string
+	// Missing lists certificate files the profile expects but that do not
+	// exist yet; they have to be copied there separately.
+	Missing []string
+	// Credentials reports whether credentials were imported.
+	Credentials bool
+}
+
+// userFiles returns pointers to the certificate file settings of a user.
+func userFiles(u *User) []*string {
+	return []*string{&u.CertFile, &u.KeyFile, &u.P12File}
+}
+
+// ExportProfile exports a profile as a bundle. With a non-empty passphrase,
+// the credentials of the profile and its certificate files are included,
+// encrypted with the passphrase.
+func (c *Config) ExportProfile(name string, creds *Credentials, passphrase string) (*Bundle, error) {
+	c.sync()
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+
+	bundle := &Bundle{
+		APIVersion:   ConfigAPIVersion,
+		Kind:         BundleKind,
+		Profile:      name,
+		Tenant:       c.Tenants[ctx.Tenant],
+		User:         c.Users[ctx.User],
+		Namespace:    ctx.Namespace,
+		OutputFormat: ctx.OutputFormat,
+		Protected:    ctx.Protected,
+		ReadOnly:     ctx.ReadOnly,
+	}
+
+	// OIDC token files are provided by the machine that runs the CLI, e.g.
+	// a CI runner, and have no meaning elsewhere
+	bundle.User.OIDCTokenFile = ""
+
+	secrets := bundleSecrets{Files: make(map[string][]byte)}
+	for _, file := range userFiles(&bundle.User) {
+		if *file == "" {
+			continue
+		}
+		if passphrase != "" {
+			data, err := os.ReadFile(*file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read certificate file: %w", err)
+			}
+			secrets.Files[filepath.Base(*file)] = data
+		}
+		*file = filepath.Base(*file)
+	}
+
+	if passphrase == "" {
+		return bundle, nil
+	}
+
+	if creds != nil {
+		secrets.Credentials = creds.Profiles[ctx.User]
+	}
+	// Cached session tokens are tied to this machine
+	secrets.Credentials.SessionToken = ""
+	secrets.Credentials.SessionExpiresAt = time.Time{}
+	secrets.Credentials.SessionCredential = ""
+
+	plain, err := yaml.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	if bundle.Secrets, err = encryptSecrets(plain, passphrase); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// ParseBundle reads a bundle written by ExportProfile.
+func ParseBundle(data []byte) (*Bundle, error) {
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if bundle.Kind != BundleKind {
+		return nil, fmt.Errorf("not a profile bundle (kind %q)", bundle.Kind)
+	}
+	if bundle.APIVersion != ConfigAPIVersion {
+		return nil, fmt.Errorf("unsupported bundle apiVersion %q (this version of f5xcctl supports %s)", bundle.APIVersion, ConfigAPIVersion)
+	}
+	if bundle.Profile == "" {
+		return nil, fmt.Errorf("bundle has no profile name")
+	}
+	return &bundle, nil
+}
+
+// ImportBundle merges a bundle into the configuration as a new profile and
+// its credentials into creds. Certificate file paths are re-pointed to
+// opts.CertDir, where certificate files from the bundle secrets are written.
+func (c *Config) ImportBundle(bundle *Bundle, creds *Credentials, opts ImportOptions) (*ImportResult, error) {
+	name := opts.Name
+	if name == "" {
+		name = bundle.Profile
+	}
+
+	c.sync()
+	if _, exists := c.Contexts[name]; exists && !opts.Overwrite {
+		return nil, fmt.Errorf("profile %q already exists; import under another name or overwrite it", name)
+	}
+	if _, exists := c.Users[name]; exists {
+		if _, isProfile := c.Contexts[name]; !isProfile || c.userShared(name, name) {
+			return nil, fmt.Errorf("user %q already exists and is used by other contexts; import under another name", name)
+		}
+	}
+
+	var secrets bundleSecrets
+	if bundle.Secrets != nil && opts.Passphrase != "" {
+		plain, err := decryptSecrets(bundle.Secrets, opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(plain, &secrets); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle secrets: %w", err)
+		}
+	}
+
+	result := &ImportResult{Profile: name}
+	user := bundle.User
+	for _, file := range userFiles(&user) {
+		if *file == "" {
+			continue
+		}
+		base := filepath.Base(*file)
+		*file = filepath.Join(opts.CertDir, base)
+
+		data, embedded := secrets.Files[base]
+		existing, err := os.ReadFile(*file)
+		switch {
+		case embedded && err == nil && !bytes.Equal(existing, data) && !opts.Overwrite:
+			return nil, fmt.Errorf("certificate file %s already exists with different content", *file)
+		case embedded:
+			result.Files = append(result.Files, *file)
+		case err != nil:
+			result.Missing = append(result.Missing, *file)
+		}
+	}
+
+	// Write certificate files only once nothing can fail anymore
+	if len(result.Files) > 0 {
+		if err := os.MkdirAll(opts.CertDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+		}
+	}
+	for _, path := range result.Files {
+		if err := os.WriteFile(path, secrets.Files[filepath.Base(path)], 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write certificate file: %w", err)
+		}
+	}
+
+	current := c.CurrentProfile
+	if _, exists := c.Contexts[name]; exists {
+		if err := c.DeleteProfile(name); err != nil {
+			return nil, err
+		}
+	}
+	c.CurrentProfile = current
+	c.Users[name] = user
+	ctx := Context{
+		User:         name,
+		Namespace:    bundle.Namespace,
+		OutputFormat: bundle.OutputFormat,
+		Protected:    bundle.Protected,
+		ReadOnly:     bundle.ReadOnly,
+	}
+	if bundle.Tenant != (Tenant{}) {
+		ctx.Tenant = c.addTenant(name, bundle.Tenant)
+	}
+	c.Contexts[name] = ctx
+	c.sync()
+
+	if bundle.Secrets != nil && opts.Passphrase != "" && creds != nil {
+		creds.Profiles[name] = secrets.Credentials
+		result.Credentials = true
+	}
+
+	return result, nil
+}
+
+// encryptSecrets encrypts plain with a key derived from passphrase.
+func encryptSecrets(plain []byte, passphrase string) (*EncryptedSecrets, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := bundleAEAD(passphrase, salt, bundleIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return &EncryptedSecrets{
+		Cipher:     bundleCipher,
+		KDF:        bundleKDF,
+		Iterations: bundleIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Data:       base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, nil)),
+	}, nil
+}
+
+// decryptSecrets reverses encryptSecrets.
+func decryptSecrets(secrets *EncryptedSecrets, passphrase string) ([]byte, error) {
+	if secrets.Cipher != bundleCipher || secrets.KDF != bundleKDF {
+		return nil, fmt.Errorf("unsupported bundle encryption %s/%s", secrets.Cipher, secrets.KDF)
+	}
+	if secrets.Iterations < minBundleIterations || secrets.Iterations > maxBundleIterations {
+		return nil, fmt.Errorf("unsupported bundle key derivation: %d iterations (must be between %d and %d)", secrets.Iterations, minBundleIterations, maxBundleIterations)
+	}
+
+	var salt, nonce, data []byte
+	for _, field := range []struct {
+		value string
+		out   *[]byte
+	}{{secrets.Salt, &salt}, {secrets.Nonce, &nonce}, {secrets.Data, &data}} {
+		decoded, err := base64.StdEncoding.DecodeString(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode bundle secrets: %w", err)
+		}
+		*field.out = decoded
+	}
+
+	gcm, err := bundleAEAD(passphrase, salt, secrets.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func bundleAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -2,51 +2,128 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the CLI configuration.
+//
+// The configuration is made of tenants, users and contexts that combine a
+// tenant with a user and defaults such as the namespace. A profile is the
+// flattened view of a context: Profiles holds one per context and
+// CurrentProfile names the current context. Configurations that only set
+// Profiles (the layout of older versions) are migrated into contexts on
+// first use; afterwards Profiles is a read-only view.
 type Config struct {
-	CurrentProfile string             `yaml:"current-profile"`
-	Profiles       map[string]Profile `yaml:"profiles"`
+	CurrentProfile string
+	Profiles       map[string]Profile
+
+	Tenants  map[string]Tenant
+	Users    map[string]User
+	Contexts map[string]Context
+
+	// Aliases maps custom subcommands to the command lines they expand to.
+	// $1, $2, ... are replaced by positional arguments and $@ by all of them.
+	Aliases map[string]string
+	// Defaults maps command paths such as "get" or "config view" to flags
+	// added to every invocation that does not set them explicitly.
+	Defaults map[string]string
+
+	path      string            // file the configuration was loaded from
+	overrides map[string]string // flag and environment values, never saved
+	migration *MigrationPlan    // pending upgrade of the loaded file
+	project   *Project          // per-project settings, never saved
 }
 
-// Profile represents a configuration profile.
+// Profile represents the effective settings of a context.
 type Profile struct {
 	Tenant           string `yaml:"tenant"`
 	APIURL           string `yaml:"api-url"`
-	AuthMethod       string `yaml:"auth-method"` // "api-token", "certificate", "p12", "sso"
+	AuthMethod       string `yaml:"auth-method"` // "api-token", "certificate", "p12", "sso", "oidc-federation"
 	CertFile         string `yaml:"cert-file,omitempty"`
 	KeyFile          string `yaml:"key-file,omitempty"`
 	P12File          string `yaml:"p12-file,omitempty"`
 	DefaultNamespace string `yaml:"default-namespace"`
 	OutputFormat     string `yaml:"output-format"`
+	// CertExpiryWarningDays is how many days before client certificate expiry
+	// to start warning (0 uses the default of 30, negative disables warnings).
+	CertExpiryWarningDays int `yaml:"cert-expiry-warning-days,omitempty"`
+	// TokenExchange exchanges the client certificate for a short-lived API
+	// token on first use and authenticates with the cached token afterwards.
+	TokenExchange bool `yaml:"token-exchange,omitempty"`
+	// OIDC workload identity federation settings (auth-method: oidc-federation).
+	OIDCTokenFile   string `yaml:"oidc-token-file,omitempty"`
+	OIDCTokenEnv    string `yaml:"oidc-token-env,omitempty"`
+	OIDCExchangeURL string `yaml:"oidc-exchange-url,omitempty"`
+	OIDCAudience    string `yaml:"oidc-audience,omitempty"`
+	// Protected requires typing the tenant name to confirm mutations.
+	Protected bool `yaml:"protected,omitempty"`
+	// ReadOnly refuses mutations altogether.
+	ReadOnly bool `yaml:"read-only,omitempty"`
 }
 
 // Credentials represents stored credentials (separate file with restricted permissions).
+// Profiles is keyed by user name.
 type Credentials struct {
-	Profiles map[string]ProfileCredentials `yaml:"profiles"`
+	APIVersion string                        `yaml:"apiVersion"`
+	Profiles   map[string]ProfileCredentials `yaml:"profiles"`
+
+	path      string         // file the credentials were loaded from
+	migration *MigrationPlan // pending upgrade of the loaded file
 }
 
 // ProfileCredentials represents credentials for a profile.
 type ProfileCredentials struct {
 	APIToken    string    `yaml:"api-token,omitempty"`
 	P12Password string    `yaml:"p12-password,omitempty"`
+	KeyPassword string    `yaml:"key-password,omitempty"`
 	ExpiresAt   time.Time `yaml:"expires-at,omitempty"`
+	// SessionToken caches a token obtained by certificate token exchange.
+	SessionToken      string    `yaml:"session-token,omitempty"`
+	SessionExpiresAt  time.Time `yaml:"session-expires-at,omitempty"`
+	SessionCredential string    `yaml:"session-credential,omitempty"`
 }
 
+// ErrConfigNotFound is returned by Load when the configuration file does not exist.
+var ErrConfigNotFound = errors.New("configuration file not found")
+
+// ErrCredentialsNotFound is returned by LoadCredentialsFrom when the
+// credentials file does not exist. It matches os.ErrNotExist.
+var ErrCredentialsNotFound = fmt.Errorf("credentials file not found: %w", os.ErrNotExist)
+
+// Environment variables that relocate the configuration files.
+const (
+	// EnvConfigFile overrides the configuration file path.
+	EnvConfigFile = "F5XC_CONFIG"
+	// EnvCredentialsFile overrides the credentials file path.
+	EnvCredentialsFile = "F5XC_CREDENTIALS"
+)
+
 // DefaultConfigDir returns the default configuration directory.
+// It is ~/.f5xc, or $XDG_CONFIG_HOME/f5xc when XDG_CONFIG_HOME is set and
+// ~/.f5xc does not already exist.
 func DefaultConfigDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".f5xc"
+	legacyDir := ".f5xc"
+	if home, err := os.UserHomeDir(); err == nil {
+		legacyDir = filepath.Join(home, ".f5xc")
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		if _, err := os.Stat(legacyDir); os.IsNotExist(err) {
+			return filepath.Join(xdg, "f5xc")
+		}
 	}
-	return filepath.Join(home, ".f5xc")
+
+	return legacyDir
 }
 
 // DefaultConfigPath returns the default configuration file path.
@@ -56,45 +133,93 @@ func DefaultConfigPath() string {
 
 // DefaultCredentialsPath returns the default credentials file path.
 func DefaultCredentialsPath() string {
-	return filepath.Join(DefaultConfigDir(), "credentials")
+	return ResolveCredentialsPath("")
+}
+
+// ResolveConfigPath returns the configuration file to use. An explicit path
+// (the --config flag) wins over F5XC_CONFIG, which wins over the default.
+func ResolveConfigPath(configFile string) string {
+	if configFile != "" {
+		return configFile
+	}
+	if env := os.Getenv(EnvConfigFile); env != "" {
+		return env
+	}
+	return DefaultConfigPath()
+}
+
+// ResolveCredentialsPath returns the credentials file that belongs to the
+// given configuration file: F5XC_CREDENTIALS if set, otherwise a
+// "credentials" file next to it. An empty configPath is resolved first.
+func ResolveCredentialsPath(configPath string) string {
+	if env := os.Getenv(EnvCredentialsFile); env != "" {
+		return env
+	}
+	if configPath == "" {
+		configPath = ResolveConfigPath("")
+	}
+	return filepath.Join(filepath.Dir(configPath), "credentials")
 }
 
 // NewDefault creates a new default configuration.
 func NewDefault() *Config {
-	return &Config{
+	cfg := &Config{
 		CurrentProfile: "default",
-		Profiles: map[string]Profile{
+		Users: map[string]User{
+			"default": {AuthMethod: "api-token"},
+		},
+		Contexts: map[string]Context{
 			"default": {
-				AuthMethod:       "api-token",
-				DefaultNamespace: "default",
-				OutputFormat:     "table",
+				User:         "default",
+				Namespace:    "default",
+				OutputFormat: "table",
 			},
 		},
 	}
+	cfg.sync()
+	return cfg
 }
 
-// Load loads the configuration from file.
-func Load(configFile, profileName string) (*Config, error) {
-	if configFile == "" {
-		configFile = DefaultConfigPath()
+// NewCredentials creates an empty credentials set stored at path.
+func NewCredentials(path string) *Credentials {
+	return &Credentials{
+		APIVersion: CredentialsAPIVersion,
+		Profiles:   make(map[string]ProfileCredentials),
+		path:       path,
 	}
+}
+
+// Load loads the configuration from file. An empty configFile is resolved
+// with ResolveConfigPath. Files written by older versions are migrated in
+// memory; the file itself is upgraded, after a backup, on the next Save.
+func Load(configFile, profileName string) (*Config, error) {
+	configFile = ResolveConfigPath(configFile)
 
 	data, err := os.ReadFile(configFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("configuration file not found: %s (run 'f5xcctl configure')", configFile)
+			return nil, fmt.Errorf("%w: %s (run 'f5xcctl configure')", ErrConfigNotFound, configFile)
 		}
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	plan, err := planMigration(configFile, data, configMigrations, ConfigAPIVersion, canonicalConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := yaml.Unmarshal(plan.Migrated, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	cfg.path = configFile
+	if plan.Pending() {
+		cfg.migration = plan
+	}
 
 	// Override current profile if specified
 	if profileName != "" {
-		if _, ok := cfg.Profiles[profileName]; !ok {
+		if _, ok := cfg.Contexts[profileName]; !ok {
 			return nil, fmt.Errorf("profile %q not found in configuration", profileName)
 		}
 		cfg.CurrentProfile = profileName
@@ -103,10 +228,11 @@ func Load(configFile, profileName string) (*Config, error) {
 	return &cfg, nil
 }
 
-// Save saves the configuration to file.
+// Save saves the configuration to the file it was loaded from, or to the
+// resolved default location for new configurations.
 func Save(cfg *Config) error {
-	configDir := DefaultConfigDir()
-	if err := os.MkdirAll(configDir, 0o700); err != nil {
+	configPath := cfg.Path()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -115,70 +241,171 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	configPath := DefaultConfigPath()
+	if cfg.migration != nil && cfg.migration.Path == configPath {
+		if err := cfg.migration.backup(); err != nil {
+			return err
+		}
+	}
+
 	if err := os.WriteFile(configPath, data, 0o600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	cfg.path = configPath
+	cfg.migration = nil
 	return nil
 }
 
-// LoadCredentials loads credentials from file.
+// LoadCredentials loads credentials from the default credentials file.
 func LoadCredentials() (*Credentials, error) {
-	credsPath := DefaultCredentialsPath()
+	return LoadCredentialsFrom(DefaultCredentialsPath())
+}
 
+// LoadCredentialsFrom loads credentials from the given file. Like Load, it
+// migrates older files in memory and upgrades them on the next save.
+func LoadCredentialsFrom(credsPath string) (*Credentials, error) {
 	data, err := os.ReadFile(credsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("credentials file not found")
+			return nil, fmt.Errorf("%w: %s", ErrCredentialsNotFound, credsPath)
 		}
 		return nil, fmt.Errorf("failed to read credentials file: %w", err)
 	}
 
+	plan, err := planMigration(credsPath, data, credentialsMigrations, CredentialsAPIVersion, canonicalCredentials)
+	if err != nil {
+		return nil, err
+	}
+
 	var creds Credentials
-	if err := yaml.Unmarshal(data, &creds); err != nil {
+	if err := yaml.Unmarshal(plan.Migrated, &creds); err != nil {
 		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
 	}
+	if creds.Profiles == nil {
+		creds.Profiles = make(map[string]ProfileCredentials)
+	}
+	creds.path = credsPath
+	if plan.Pending() {
+		creds.migration = plan
+	}
 
 	return &creds, nil
 }
 
-// SaveCredentials saves credentials to file with restricted permissions.
+// SaveCredentials saves credentials with restricted permissions to the file
+// they were loaded from, or to the default credentials file.
 func SaveCredentials(creds *Credentials) error {
-	configDir := DefaultConfigDir()
-	if err := os.MkdirAll(configDir, 0o700); err != nil {
+	credsPath := creds.Path()
+	if err := os.MkdirAll(filepath.Dir(credsPath), 0o700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	creds.APIVersion = CredentialsAPIVersion
 	data, err := yaml.Marshal(creds)
 	if err != nil {
 		return fmt.Errorf("failed to marshal credentials: %w", err)
 	}
 
-	credsPath := DefaultCredentialsPath()
+	if creds.migration != nil && creds.migration.Path == credsPath {
+		if err := creds.migration.backup(); err != nil {
+			return err
+		}
+	}
+
 	if err := os.WriteFile(credsPath, data, 0o600); err != nil {
 		return fmt.Errorf("failed to write credentials file: %w", err)
 	}
 
+	creds.path = credsPath
+	creds.migration = nil
 	return nil
 }
 
+// Path returns the file the configuration was loaded from or will be saved to.
+func (c *Config) Path() string {
+	if c.path == "" {
+		return ResolveConfigPath("")
+	}
+	return c.path
+}
+
+// SetPath sets the file the configuration will be saved to.
+func (c *Config) SetPath(path string) {
+	c.path = path
+}
+
+// CredentialsPath returns the credentials file that belongs to this configuration.
+func (c *Config) CredentialsPath() string {
+	return ResolveCredentialsPath(c.Path())
+}
+
+// Path returns the file the credentials were loaded from or will be saved to.
+func (c *Credentials) Path() string {
+	if c.path == "" {
+		return DefaultCredentialsPath()
+	}
+	return c.path
+}
+
 // GetCurrentProfile returns the current profile configuration.
 func (c *Config) GetCurrentProfile() *Profile {
 	if c == nil {
 		return nil
 	}
+	c.sync()
 	profile, ok := c.Profiles[c.CurrentProfile]
 	if !ok {
 		return nil
 	}
+	for key, value := range c.overrides {
+		_ = profile.set(key, value) // validated by Override
+	}
 	return &profile
 }
 
-// Get retrieves a configuration value by key.
+// Override sets a value that takes precedence over the current profile for
+// the life of the process, such as a flag or environment variable. Overrides
+// are reflected by GetCurrentProfile but never saved.
+func (c *Config) Override(key, value string) error {
+	var profile Profile
+	if err := profile.set(key, value); err != nil {
+		return err
+	}
+
+	if c.overrides == nil {
+		c.overrides = make(map[string]string)
+	}
+	c.overrides[key] = value
+	return nil
+}
+
+// AuthMethods lists the supported values of auth-method.
+var AuthMethods = []string{"api-token", "certificate", "p12", "sso", "oidc-federation"}
+
+// Get retrieves a configuration value of the current profile by key.
 func (c *Config) Get(key string) (string, error) {
-	profile := c.GetCurrentProfile()
-	if profile == nil {
+	return c.GetProfileKey(c.CurrentProfile, key)
+}
+
+// Set sets a configuration value of the current profile by key.
+func (c *Config) Set(key, value string) error {
+	return c.SetProfileKey(c.CurrentProfile, key, value)
+}
+
+// Unset clears a configuration value of the current profile.
+func (c *Config) Unset(key string) error {
+	return c.UnsetProfileKey(c.CurrentProfile, key)
+}
+
+// GetProfileKey retrieves a configuration value of the named profile.
+func (c *Config) GetProfileKey(name, key string) (string, error) {
+	if key == "current-profile" || key == "current-context" {
+		return c.CurrentProfile, nil
+	}
+
+	c.sync()
+	profile, ok := c.Profiles[name]
+	if !ok {
 		return "", fmt.Errorf("no profile configured")
 	}
 
@@ -199,47 +426,193 @@ func (c *Config) Get(key string) (string, error) {
 		return profile.KeyFile, nil
 	case "p12-file":
 		return profile.P12File, nil
-	case "current-profile":
-		return c.CurrentProfile, nil
+	case "cert-expiry-warning-days":
+		return strconv.Itoa(profile.CertExpiryWarningDays), nil
+	case "token-exchange":
+		return strconv.FormatBool(profile.TokenExchange), nil
+	case "oidc-token-file":
+		return profile.OIDCTokenFile, nil
+	case "oidc-token-env":
+		return profile.OIDCTokenEnv, nil
+	case "oidc-exchange-url":
+		return profile.OIDCExchangeURL, nil
+	case "oidc-audience":
+		return profile.OIDCAudience, nil
+	case "protected":
+		return strconv.FormatBool(profile.Protected), nil
+	case "read-only":
+		return strconv.FormatBool(profile.ReadOnly), nil
+	case "context-tenant":
+		return c.Contexts[name].Tenant, nil
+	case "context-user":
+		return c.Contexts[name].User, nil
 	default:
 		return "", fmt.Errorf("unknown configuration key: %s", key)
 	}
 }
 
-// Set sets a configuration value by key.
-func (c *Config) Set(key, value string) error {
-	profile := c.GetCurrentProfile()
-	if profile == nil {
+// SetProfileKey validates and sets a configuration value of the named
+// profile. Authentication keys change the profile's user, which other
+// contexts may share; tenant keys never change the tenant of other contexts.
+func (c *Config) SetProfileKey(name, key, value string) error {
+	if err := ValidateValue(key, value); err != nil {
+		return err
+	}
+
+	// Store files as absolute paths so the profile works from any directory
+	switch key {
+	case "cert-file", "key-file", "p12-file", "oidc-token-file":
+		if abs, err := filepath.Abs(value); err == nil {
+			value = abs
+		}
+	}
+
+	return c.setProfileKey(name, key, value)
+}
+
+// UnsetProfileKey clears a configuration value of the named profile.
+func (c *Config) UnsetProfileKey(name, key string) error {
+	if key == "current-profile" || key == "current-context" {
+		return fmt.Errorf("%s cannot be unset; use 'f5xcctl config use-context'", key)
+	}
+	return c.setProfileKey(name, key, "")
+}
+
+func (c *Config) setProfileKey(name, key, value string) error {
+	if key == "current-profile" || key == "current-context" {
+		if _, ok := c.Contexts[value]; !ok {
+			return fmt.Errorf("profile %q does not exist", value)
+		}
+		c.CurrentProfile = value
+		return nil
+	}
+
+	c.sync()
+	ctx, ok := c.Contexts[name]
+	if !ok {
 		return fmt.Errorf("no profile configured")
 	}
 
 	switch key {
-	case "tenant":
-		profile.Tenant = value
-	case "api-url":
-		profile.APIURL = value
-	case "auth-method":
-		profile.AuthMethod = value
+	case "tenant", "api-url":
+		tenant := c.Tenants[ctx.Tenant]
+		if key == "tenant" {
+			tenant.Name = value
+		} else {
+			tenant.APIURL = value
+		}
+
+		// Re-point the context instead of editing a tenant others may use
+		if ctx.Tenant != "" && !c.tenantShared(ctx.Tenant, name) {
+			delete(c.Tenants, ctx.Tenant)
+		}
+		ctx.Tenant = ""
+		if tenant != (Tenant{}) {
+			ctx.Tenant = c.addTenant(name, tenant)
+		}
 	case "default-namespace":
-		profile.DefaultNamespace = value
+		ctx.Namespace = value
 	case "output-format":
-		profile.OutputFormat = value
+		ctx.OutputFormat = value
+	case "protected", "read-only":
+		enabled, err := parseBoolSetting(key, value)
+		if err != nil {
+			return err
+		}
+		if key == "protected" {
+			ctx.Protected = enabled
+		} else {
+			ctx.ReadOnly = enabled
+		}
+	default:
+		if ctx.User == "" {
+			ctx.User = name
+		}
+		user := c.Users[ctx.User]
+		if err := user.set(key, value); err != nil {
+			return err
+		}
+		c.Users[ctx.User] = user
+	}
+
+	c.Contexts[name] = ctx
+	c.sync()
+	return nil
+}
+
+// set sets an authentication key on the user. An empty value clears it.
+func (u *User) set(key, value string) error {
+	switch key {
+	case "auth-method":
+		u.AuthMethod = value
 	case "cert-file":
-		profile.CertFile = value
+		u.CertFile = value
 	case "key-file":
-		profile.KeyFile = value
+		u.KeyFile = value
 	case "p12-file":
-		profile.P12File = value
-	case "current-profile":
-		if _, ok := c.Profiles[value]; !ok {
-			return fmt.Errorf("profile %q does not exist", value)
+		u.P12File = value
+	case "cert-expiry-warning-days":
+		days := 0
+		if value != "" {
+			var err error
+			if days, err = strconv.Atoi(value); err != nil {
+				return fmt.Errorf("invalid value for %s: %q is not an integer", key, value)
+			}
 		}
-		c.CurrentProfile = value
-		return nil
+		u.CertExpiryWarningDays = days
+	case "token-exchange":
+		enabled, err := parseBoolSetting(key, value)
+		if err != nil {
+			return err
+		}
+		u.TokenExchange = enabled
+	case "oidc-token-file":
+		u.OIDCTokenFile = value
+	case "oidc-token-env":
+		u.OIDCTokenEnv = value
+	case "oidc-exchange-url":
+		u.OIDCExchangeURL = value
+	case "oidc-audience":
+		u.OIDCAudience = value
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
+	return nil
+}
+
+// parseBoolSetting parses a boolean setting; an empty value means false.
+func parseBoolSetting(key, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %q is not a boolean", key, value)
+	}
+	return enabled, nil
+}
 
-	c.Profiles[c.CurrentProfile] = *profile
+// ValidateValue checks the shape of a value for a profile key before it is
+// stored.
+func ValidateValue(key, value string) error {
+	switch key {
+	case "api-url", "oidc-exchange-url":
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid value for %s: %q is not an http(s) URL", key, value)
+		}
+	case "auth-method":
+		if !slices.Contains(AuthMethods, value) {
+			return fmt.Errorf("invalid value for %s: %q (must be one of: %s)", key, value, strings.Join(AuthMethods, ", "))
+		}
+	case "cert-file", "key-file", "p12-file", "oidc-token-file":
+		info, err := os.Stat(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("invalid value for %s: %s is a directory", key, value)
+		}
+	}
 	return nil
 }
@@ -1,13 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // setTestHome sets the home directory environment variable for testing.
@@ -16,6 +19,11 @@ import (
 func setTestHome(t *testing.T, dir string) func() {
 	t.Helper()
 
+	// Keep the caller's environment from relocating the config files
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(EnvConfigFile, "")
+	t.Setenv(EnvCredentialsFile, "")
+
 	if runtime.GOOS == "windows" {
 		original := os.Getenv("USERPROFILE")
 		os.Setenv("USERPROFILE", dir)
@@ -216,3 +224,727 @@ func TestCredentials(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "test-token", loaded.Profiles["default"].APIToken)
 }
+
+func TestResolveConfigPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	legacy := filepath.Join(tmpDir, ".f5xc", "config.yaml")
+	xdgDir := filepath.Join(tmpDir, "xdg")
+
+	assert.Equal(t, legacy, ResolveConfigPath(""))
+
+	t.Setenv("XDG_CONFIG_HOME", xdgDir)
+	assert.Equal(t, filepath.Join(xdgDir, "f5xc", "config.yaml"), ResolveConfigPath(""))
+
+	// An existing ~/.f5xc keeps precedence over XDG_CONFIG_HOME
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0o700))
+	assert.Equal(t, legacy, ResolveConfigPath(""))
+
+	t.Setenv(EnvConfigFile, "/env/config.yaml")
+	assert.Equal(t, "/env/config.yaml", ResolveConfigPath(""))
+	assert.Equal(t, "/flag/config.yaml", ResolveConfigPath("/flag/config.yaml"))
+}
+
+func TestResolveCredentialsPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	assert.Equal(t, filepath.Join(tmpDir, ".f5xc", "credentials"), ResolveCredentialsPath(""))
+	assert.Equal(t, filepath.Join("custom", "credentials"), ResolveCredentialsPath(filepath.Join("custom", "config.yaml")))
+
+	t.Setenv(EnvCredentialsFile, "/env/credentials")
+	assert.Equal(t, "/env/credentials", ResolveCredentialsPath(filepath.Join("custom", "config.yaml")))
+}
+
+func TestSaveRoundTripsToLoadedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	configPath := filepath.Join(tmpDir, "custom", "f5xc.yaml")
+	cfg := NewDefault()
+	cfg.SetPath(configPath)
+	require.NoError(t, Save(cfg))
+
+	loaded, err := Load(configPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, configPath, loaded.Path())
+
+	require.NoError(t, loaded.Set("tenant", "custom-tenant"))
+	require.NoError(t, Save(loaded))
+
+	// Nothing is written to the default location
+	_, err = os.Stat(DefaultConfigPath())
+	assert.True(t, os.IsNotExist(err))
+
+	reloaded, err := Load(configPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, "custom-tenant", reloaded.GetCurrentProfile().Tenant)
+
+	// Credentials live next to the config file they belong to
+	creds := NewCredentials(loaded.CredentialsPath())
+	creds.Profiles["default"] = ProfileCredentials{APIToken: "custom-token"}
+	require.NoError(t, SaveCredentials(creds))
+
+	loadedCreds, err := LoadCredentialsFrom(filepath.Join(tmpDir, "custom", "credentials"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom-token", loadedCreds.Profiles["default"].APIToken)
+	_, err = LoadCredentials()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	configPath := filepath.Join(tmpDir, "env-config.yaml")
+	t.Setenv(EnvConfigFile, configPath)
+
+	require.NoError(t, Save(NewDefault()))
+	_, err := os.Stat(configPath)
+	require.NoError(t, err)
+
+	cfg, err := Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, configPath, cfg.Path())
+}
+
+func TestLoadMigratesProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	legacy := `current-profile: prod
+profiles:
+  prod:
+    tenant: acme
+    api-url: https://acme.console.ves.volterra.io
+    auth-method: api-token
+    default-namespace: shop
+    output-format: table
+  staging:
+    tenant: acme
+    api-url: https://acme.console.ves.volterra.io
+    auth-method: p12
+    p12-file: /certs/staging.p12
+    default-namespace: staging
+  other:
+    tenant: acme
+    api-url: https://acme.staging.volterra.us
+    auth-method: api-token
+`
+	configPath := DefaultConfigPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o700))
+	require.NoError(t, os.WriteFile(configPath, []byte(legacy), 0o600))
+
+	cfg, err := Load("", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", cfg.CurrentProfile)
+	assert.Equal(t, "prod", cfg.CurrentUser())
+	assert.Equal(t, []string{"other", "prod", "staging"}, cfg.ContextNames())
+
+	// Identical tenants are shared, conflicting ones get their own entry
+	assert.Len(t, cfg.Tenants, 2)
+	assert.Equal(t, cfg.Contexts["prod"].Tenant, cfg.Contexts["staging"].Tenant)
+	assert.NotEqual(t, cfg.Contexts["prod"].Tenant, cfg.Contexts["other"].Tenant)
+
+	// The resolved profiles match the original ones
+	staging := cfg.Profiles["staging"]
+	assert.Equal(t, "acme", staging.Tenant)
+	assert.Equal(t, "p12", staging.AuthMethod)
+	assert.Equal(t, "/certs/staging.p12", staging.P12File)
+	assert.Equal(t, "staging", staging.DefaultNamespace)
+	assert.Equal(t, "https://acme.staging.volterra.us", cfg.Profiles["other"].APIURL)
+
+	// Saving writes the new layout
+	require.NoError(t, Save(cfg))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "apiVersion: "+ConfigAPIVersion)
+	assert.Contains(t, string(data), "current-context: prod")
+	assert.Contains(t, string(data), "contexts:")
+	assert.NotContains(t, string(data), "profiles:")
+
+	// The original file is kept as a backup
+	backup, err := os.ReadFile(configPath + ".legacy.bak")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(backup))
+
+	reloaded, err := Load("", "staging")
+	require.NoError(t, err)
+	assert.Equal(t, staging, *reloaded.GetCurrentProfile())
+}
+
+func TestMigrationPlans(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	legacyConfig := "current-profile: default\nprofiles:\n  default:\n    tenant: acme\n    api-url: https://acme.console.ves.volterra.io\n"
+	legacyCreds := "profiles:\n  default:\n    api-token: secret\n"
+
+	tests := []struct {
+		name    string
+		content string
+		plan    func(string) (*MigrationPlan, error)
+		pending bool
+		wantErr string
+	}{
+		{name: "legacy config", content: legacyConfig, plan: PlanConfigMigration, pending: true},
+		{name: "current config", content: "apiVersion: f5xcctl/v1\ncurrent-context: default\n", plan: PlanConfigMigration},
+		{name: "legacy credentials", content: legacyCreds, plan: PlanCredentialsMigration, pending: true},
+		{name: "current credentials", content: "apiVersion: f5xcctl/v1\n" + legacyCreds, plan: PlanCredentialsMigration},
+		{name: "newer config", content: "apiVersion: f5xcctl/v9\n", plan: PlanConfigMigration, wantErr: "unsupported apiVersion"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, fmt.Sprintf("file-%d.yaml", i))
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			plan, err := tt.plan(path)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.pending, plan.Pending())
+
+			require.NoError(t, plan.Apply())
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if !tt.pending {
+				assert.Equal(t, tt.content, string(data))
+				assert.NoFileExists(t, plan.BackupPath())
+				return
+			}
+
+			assert.Contains(t, string(data), "apiVersion: f5xcctl/v1")
+			backup, err := os.ReadFile(path + ".legacy.bak")
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(backup))
+
+			// A migrated file has nothing left to migrate
+			again, err := tt.plan(path)
+			require.NoError(t, err)
+			assert.False(t, again.Pending())
+		})
+	}
+}
+
+func TestLoadCredentialsMigrates(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  default:\n    api-token: secret\n"), 0o600))
+
+	creds, err := LoadCredentialsFrom(path)
+	require.NoError(t, err)
+	assert.Equal(t, CredentialsAPIVersion, creds.APIVersion)
+	assert.Equal(t, "secret", creds.Profiles["default"].APIToken)
+
+	// Loading does not touch the file, saving upgrades it after a backup
+	assert.NoFileExists(t, path+".legacy.bak")
+	require.NoError(t, SaveCredentials(creds))
+	assert.FileExists(t, path+".legacy.bak")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "apiVersion: "+CredentialsAPIVersion)
+}
+
+func TestContexts(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetTenant("acme", Tenant{Name: "acme", APIURL: "https://acme.console.ves.volterra.io"})
+	cfg.SetUser("ci", User{AuthMethod: "api-token"})
+
+	require.NoError(t, cfg.SetContext("shop", Context{Tenant: "acme", User: "ci", Namespace: "shop"}))
+	require.NoError(t, cfg.SetContext("checkout", Context{Tenant: "acme", User: "ci", Namespace: "checkout"}))
+	assert.Error(t, cfg.SetContext("bad", Context{Tenant: "missing"}))
+	assert.Error(t, cfg.SetContext("bad", Context{User: "missing"}))
+
+	require.NoError(t, cfg.UseContext("shop"))
+	assert.Equal(t, "shop", cfg.GetCurrentProfile().DefaultNamespace)
+	assert.Equal(t, "ci", cfg.CurrentUser())
+	assert.Error(t, cfg.UseContext("missing"))
+
+	// Users are shared between contexts
+	require.NoError(t, cfg.Set("auth-method", "p12"))
+	assert.Equal(t, "p12", cfg.Profiles["checkout"].AuthMethod)
+
+	// Namespaces are per context
+	require.NoError(t, cfg.Set("default-namespace", "shop-v2"))
+	assert.Equal(t, "checkout", cfg.Profiles["checkout"].DefaultNamespace)
+
+	require.NoError(t, cfg.RenameContext("shop", "storefront"))
+	assert.Equal(t, "storefront", cfg.CurrentProfile)
+	assert.Equal(t, "shop-v2", cfg.GetCurrentProfile().DefaultNamespace)
+	assert.Error(t, cfg.RenameContext("storefront", "checkout"))
+	assert.Error(t, cfg.RenameContext("missing", "other"))
+
+	require.NoError(t, cfg.DeleteContext("storefront"))
+	assert.Empty(t, cfg.CurrentProfile)
+	assert.Nil(t, cfg.GetCurrentProfile())
+	require.NoError(t, cfg.DeleteContext("checkout"))
+	assert.Empty(t, cfg.ContextNames())
+	assert.Contains(t, cfg.Users, "ci")
+	assert.Error(t, cfg.DeleteContext("checkout"))
+}
+
+func TestSetValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	p12File := filepath.Join(tmpDir, "client.p12")
+	require.NoError(t, os.WriteFile(p12File, []byte("p12"), 0o600))
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{"valid url", "api-url", "https://acme.console.ves.volterra.io", ""},
+		{"url without scheme", "api-url", "acme.console.ves.volterra.io", "not an http(s) URL"},
+		{"url with other scheme", "oidc-exchange-url", "ftp://example.com", "not an http(s) URL"},
+		{"valid auth method", "auth-method", "p12", ""},
+		{"unknown auth method", "auth-method", "password", "must be one of"},
+		{"existing file", "p12-file", p12File, ""},
+		{"missing file", "cert-file", filepath.Join(tmpDir, "missing.pem"), "no such file"},
+		{"directory", "key-file", tmpDir, "is a directory"},
+		{"integer", "cert-expiry-warning-days", "ten", "not an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefault()
+			err := cfg.Set(tt.key, tt.value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				value, err := cfg.Get(tt.key)
+				require.NoError(t, err)
+				assert.Equal(t, tt.value, value)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnset(t *testing.T) {
+	cfg := NewDefault()
+	require.NoError(t, cfg.Set("tenant", "acme"))
+	require.NoError(t, cfg.Set("token-exchange", "true"))
+
+	require.NoError(t, cfg.Unset("tenant"))
+	require.NoError(t, cfg.Unset("token-exchange"))
+	require.NoError(t, cfg.Unset("default-namespace"))
+
+	profile := cfg.GetCurrentProfile()
+	assert.Empty(t, profile.Tenant)
+	assert.False(t, profile.TokenExchange)
+	assert.Empty(t, profile.DefaultNamespace)
+	assert.Empty(t, cfg.Tenants)
+
+	assert.Error(t, cfg.Unset("current-profile"))
+	assert.Error(t, cfg.Unset("unknown-key"))
+}
+
+func TestProfileManagement(t *testing.T) {
+	cfg := NewDefault()
+	require.NoError(t, cfg.Set("tenant", "acme"))
+
+	// Create a second profile without switching to it
+	require.NoError(t, cfg.CreateProfile("staging"))
+	require.NoError(t, cfg.SetProfileKey("staging", "tenant", "acme-staging"))
+	require.NoError(t, cfg.SetProfileKey("staging", "auth-method", "sso"))
+	assert.Equal(t, "default", cfg.CurrentProfile)
+	assert.Equal(t, "acme", cfg.GetCurrentProfile().Tenant)
+	assert.Equal(t, "acme-staging", cfg.Profiles["staging"].Tenant)
+	assert.Error(t, cfg.CreateProfile("staging"))
+
+	// Copies get their own user but share the tenant
+	require.NoError(t, cfg.CopyProfile("default", "shop"))
+	assert.Equal(t, "shop", cfg.Contexts["shop"].User)
+	assert.Equal(t, cfg.Contexts["default"].Tenant, cfg.Contexts["shop"].Tenant)
+	require.NoError(t, cfg.SetProfileKey("shop", "auth-method", "p12"))
+	assert.Equal(t, "api-token", cfg.GetCurrentProfile().AuthMethod)
+	assert.Error(t, cfg.CopyProfile("missing", "other"))
+	assert.Error(t, cfg.CopyProfile("default", "staging"))
+
+	// Changing the tenant of one profile leaves the other alone
+	require.NoError(t, cfg.SetProfileKey("shop", "tenant", "acme-shop"))
+	assert.Equal(t, "acme", cfg.GetCurrentProfile().Tenant)
+	assert.Equal(t, "acme-shop", cfg.Profiles["shop"].Tenant)
+
+	// Renaming takes the profile's own user along
+	require.NoError(t, cfg.RenameProfile("shop", "storefront"))
+	assert.Equal(t, "storefront", cfg.Contexts["storefront"].User)
+	assert.Equal(t, "p12", cfg.Profiles["storefront"].AuthMethod)
+	assert.NotContains(t, cfg.Users, "shop")
+
+	// Deleting removes the user and tenant nobody else uses
+	require.NoError(t, cfg.DeleteProfile("storefront"))
+	assert.NotContains(t, cfg.Users, "storefront")
+	assert.NotContains(t, cfg.Tenants, "acme-shop")
+	assert.Contains(t, cfg.Tenants, "acme")
+	assert.Error(t, cfg.DeleteProfile("storefront"))
+}
+
+func TestProfileProtection(t *testing.T) {
+	cfg := NewDefault()
+	require.NoError(t, cfg.Set("tenant", "acme"))
+	require.NoError(t, cfg.CopyProfile("default", "prod"))
+
+	require.NoError(t, cfg.SetProfileKey("prod", "protected", "true"))
+	require.NoError(t, cfg.SetProfileKey("prod", "read-only", "1"))
+	assert.Error(t, cfg.SetProfileKey("prod", "read-only", "yes"))
+	assert.True(t, cfg.Profiles["prod"].ReadOnly)
+	assert.True(t, cfg.Profiles["prod"].Protected)
+	assert.False(t, cfg.GetCurrentProfile().Protected, "protection belongs to the context, not the shared tenant")
+
+	value, err := cfg.GetProfileKey("prod", "protected")
+	require.NoError(t, err)
+	assert.Equal(t, "true", value)
+
+	require.NoError(t, cfg.UnsetProfileKey("prod", "protected"))
+	assert.False(t, cfg.Profiles["prod"].Protected)
+}
+
+func TestResolveSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup := setTestHome(t, tmpDir)
+	defer cleanup()
+
+	for _, name := range []string{"F5XC_TENANT", "F5XC_NAMESPACE", "F5XC_DEFAULT_NAMESPACE", "F5XC_API_URL", "F5XC_AUTH_METHOD",
+		"F5XC_API_P12_FILE", "F5XC_P12_FILE", "F5XC_CERT_FILE", "F5XC_KEY_FILE", "F5XC_OIDC_EXCHANGE_URL", "F5XC_API_TOKEN",
+		"F5XC_OUTPUT", "F5XC_OUTPUT_FORMAT", "F5XC_CERT_EXPIRY_WARNING_DAYS", "F5XC_PROTECTED", "F5XC_READ_ONLY"} {
+		t.Setenv(name, "")
+	}
+
+	cfg := NewDefault()
+	require.NoError(t, cfg.Set("tenant", "profile-tenant"))
+	require.NoError(t, cfg.Set("api-url", "https://profile.console.ves.volterra.io"))
+	require.NoError(t, cfg.Set("default-namespace", "profile-ns"))
+	require.NoError(t, cfg.Unset("output-format"))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("tenant", "", "")
+	flags.String("namespace", "", "")
+	flags.String("output", "table", "")
+	require.NoError(t, flags.Parse([]string{"--tenant", "flag-tenant"}))
+
+	t.Setenv("F5XC_TENANT", "env-tenant")
+	t.Setenv("F5XC_NAMESPACE", "env-ns")
+	t.Setenv("F5XC_API_P12_FILE", "/certs/ci.p12")
+
+	settings, err := ResolveSettings(cfg, flags)
+	require.NoError(t, err)
+
+	byKey := make(map[string]Setting)
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+
+	assert.Equal(t, Setting{Key: "tenant", Value: "flag-tenant", Origin: OriginFlag, Source: "--tenant"}, byKey["tenant"])
+	assert.Equal(t, Setting{Key: "default-namespace", Value: "env-ns", Origin: OriginEnv, Source: "F5XC_NAMESPACE"}, byKey["default-namespace"])
+	assert.Equal(t, Setting{Key: "api-url", Value: "https://profile.console.ves.volterra.io", Origin: OriginProfile, Source: "default"}, byKey["api-url"])
+	assert.Equal(t, Setting{Key: "output-format", Value: "table", Origin: OriginDefault}, byKey["output-format"])
+	assert.Equal(t, "p12", byKey["auth-method"].Value)
+	assert.Equal(t, "inferred from F5XC_API_P12_FILE", byKey["auth-method"].Source)
+	assert.NotContains(t, byKey, "key-file")
+
+	// Overrides apply to the current profile but are never saved
+	profile := cfg.GetCurrentProfile()
+	assert.Equal(t, "flag-tenant", profile.Tenant)
+	assert.Equal(t, "env-ns", profile.DefaultNamespace)
+	assert.Equal(t, "p12", profile.AuthMethod)
+	assert.Equal(t, "/certs/ci.p12", profile.P12File)
+
+	value, err := cfg.Get("tenant")
+	require.NoError(t, err)
+	assert.Equal(t, "profile-tenant", value)
+
+	require.NoError(t, Save(cfg))
+	loaded, err := Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "profile-tenant", loaded.GetCurrentProfile().Tenant)
+	assert.Equal(t, "api-token", loaded.GetCurrentProfile().AuthMethod)
+}
+
+func TestResolveSettingsExplicitAuthMethod(t *testing.T) {
+	t.Setenv("F5XC_AUTH_METHOD", "certificate")
+	t.Setenv("F5XC_API_TOKEN", "token")
+	t.Setenv("F5XC_CERT_EXPIRY_WARNING_DAYS", "")
+
+	cfg := NewDefault()
+	_, err := ResolveSettings(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "certificate", cfg.GetCurrentProfile().AuthMethod)
+
+	t.Setenv("F5XC_CERT_EXPIRY_WARNING_DAYS", "soon")
+	_, err = ResolveSettings(NewDefault(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "F5XC_CERT_EXPIRY_WARNING_DAYS")
+}
+
+func TestResolveSettingsGuard(t *testing.T) {
+	tests := []struct {
+		name        string
+		stored      string
+		env         string
+		wantEnabled bool
+		wantOrigin  string
+	}{
+		{name: "env cannot unprotect", stored: "true", env: "false", wantEnabled: true, wantOrigin: OriginProfile},
+		{name: "env can protect", stored: "false", env: "true", wantEnabled: true, wantOrigin: OriginEnv},
+		{name: "env false on unprotected profile", stored: "false", env: "false", wantEnabled: false, wantOrigin: OriginEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"protected", "read-only"} {
+				env := settingEnv[key][0]
+				t.Setenv("F5XC_PROTECTED", "")
+				t.Setenv("F5XC_READ_ONLY", "")
+				t.Setenv(env, tt.env)
+
+				cfg := NewDefault()
+				require.NoError(t, cfg.Set(key, tt.stored))
+				settings, err := ResolveSettings(cfg, nil)
+				require.NoError(t, err)
+
+				profile := cfg.GetCurrentProfile()
+				enabled := profile.Protected
+				if key == "read-only" {
+					enabled = profile.ReadOnly
+				}
+				assert.Equal(t, tt.wantEnabled, enabled, key)
+				for _, setting := range settings {
+					if setting.Key == key {
+						assert.Equal(t, tt.wantOrigin, setting.Origin, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "apps", "shop")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+
+	project, err := FindProject(nested)
+	require.NoError(t, err)
+	assert.Nil(t, project)
+
+	content := "profile: prod\nnamespace: shop\nselector: team=payments\nmanifest-dirs:\n  - manifests\n  - /abs/dir\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, ProjectFileName), []byte(content), 0o600))
+
+	project, err = FindProject(nested)
+	require.NoError(t, err)
+	require.NotNil(t, project)
+	assert.Equal(t, filepath.Join(root, ProjectFileName), project.Path())
+	assert.Equal(t, "prod", project.Profile)
+	assert.Equal(t, "team=payments", project.Selector)
+	assert.Equal(t, []string{filepath.Join(root, "manifests"), "/abs/dir"}, project.ManifestPaths())
+
+	// Typos are rejected
+	require.NoError(t, os.WriteFile(filepath.Join(nested, ProjectFileName), []byte("namepsace: shop\n"), 0o600))
+	_, err = FindProject(nested)
+	require.Error(t, err)
+
+	// Empty project files are fine
+	require.NoError(t, os.WriteFile(filepath.Join(nested, ProjectFileName), nil, 0o600))
+	project, err = FindProject(nested)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(nested, ProjectFileName), project.Path())
+}
+
+func TestResolveSettingsProject(t *testing.T) {
+	t.Setenv("F5XC_NAMESPACE", "")
+	t.Setenv("F5XC_DEFAULT_NAMESPACE", "")
+
+	cfg := NewDefault()
+	cfg.SetProject(&Project{Namespace: "shop", path: "/repo/.f5xcctl.yaml"})
+
+	settings, err := ResolveSettings(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", cfg.GetCurrentProfile().DefaultNamespace)
+	for _, setting := range settings {
+		if setting.Key == "default-namespace" {
+			assert.Equal(t, OriginProject, setting.Origin)
+			assert.Equal(t, "/repo/.f5xcctl.yaml", setting.Source)
+		}
+	}
+
+	// The environment wins over the project
+	t.Setenv("F5XC_NAMESPACE", "staging")
+	cfg = NewDefault()
+	cfg.SetProject(&Project{Namespace: "shop"})
+	_, err = ResolveSettings(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", cfg.GetCurrentProfile().DefaultNamespace)
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "get httplb -A", want: []string{"get", "httplb", "-A"}},
+		{line: `get httplb -o jsonpath='{.items[*].metadata.name}'`, want: []string{"get", "httplb", "-o", "jsonpath={.items[*].metadata.name}"}},
+		{line: `describe "my lb" a\ b`, want: []string{"describe", "my lb", "a b"}},
+		{line: `x ""`, want: []string{"x", ""}},
+		{line: "  ", want: nil},
+		{line: `get 'unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := SplitCommandLine(tt.line)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	cfg := &Config{Aliases: map[string]string{
+		"lbs": "get httplb -A -L env,team",
+		"lbd": "describe httplb $1 -n $2",
+		"all": "get $@ -A",
+		"sel": "get httplb -l app=$1",
+	}}
+
+	tests := []struct {
+		name    string
+		alias   string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "no arguments", alias: "lbs", want: []string{"get", "httplb", "-A", "-L", "env,team"}},
+		{name: "extra arguments appended", alias: "lbs", args: []string{"my-lb"}, want: []string{"get", "httplb", "-A", "-L", "env,team", "my-lb"}},
+		{name: "positional", alias: "lbd", args: []string{"my-lb", "prod"}, want: []string{"describe", "httplb", "my-lb", "-n", "prod"}},
+		{name: "all arguments", alias: "all", args: []string{"httplb", "x"}, want: []string{"get", "httplb", "x", "-A"}},
+		{name: "inside a word", alias: "sel", args: []string{"shop"}, want: []string{"get", "httplb", "-l", "app=shop"}},
+		{name: "missing arguments", alias: "lbd", args: []string{"my-lb"}, wantErr: "expects at least 2"},
+		{name: "unknown alias", alias: "nope", wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.ExpandAlias(tt.alias, tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProfileBundle(t *testing.T) {
+	dir := t.TempDir()
+	p12File := filepath.Join(dir, "acme.p12")
+	require.NoError(t, os.WriteFile(p12File, []byte("p12 data"), 0o600))
+
+	src := &Config{}
+	require.NoError(t, src.CreateProfile("prod"))
+	require.NoError(t, src.SetProfileKey("prod", "tenant", "acme"))
+	require.NoError(t, src.SetProfileKey("prod", "api-url", "https://acme.console.ves.volterra.io"))
+	require.NoError(t, src.SetProfileKey("prod", "auth-method", "p12"))
+	require.NoError(t, src.SetProfileKey("prod", "p12-file", p12File))
+	require.NoError(t, src.SetProfileKey("prod", "default-namespace", "shop"))
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt"), 0o600))
+	require.NoError(t, src.SetProfileKey("prod", "oidc-token-file", tokenFile))
+	srcCreds := NewCredentials(filepath.Join(dir, "credentials"))
+	srcCreds.Profiles["prod"] = ProfileCredentials{P12Password: "hunter2", SessionToken: "cached"}
+
+	// Without a passphrase no secrets are exported
+	plain, err := src.ExportProfile("prod", srcCreds, "")
+	require.NoError(t, err)
+	assert.Nil(t, plain.Secrets)
+	assert.Equal(t, "acme.p12", plain.User.P12File)
+	assert.Empty(t, plain.User.OIDCTokenFile)
+
+	bundle, err := src.ExportProfile("prod", srcCreds, "passphrase")
+	require.NoError(t, err)
+	data, err := yaml.Marshal(bundle)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "p12 data")
+
+	bundle, err = ParseBundle(data)
+	require.NoError(t, err)
+
+	// Import into a configuration that already has a prod profile
+	dst := &Config{}
+	require.NoError(t, dst.CreateProfile("prod"))
+	require.NoError(t, dst.SetProfileKey("prod", "tenant", "acme"))
+	require.NoError(t, dst.SetProfileKey("prod", "api-url", "https://acme.console.ves.volterra.io"))
+	dst.CurrentProfile = "prod"
+	dstCreds := NewCredentials(filepath.Join(dir, "dst-credentials"))
+	certDir := filepath.Join(dir, "certs")
+
+	_, err = dst.ImportBundle(bundle, dstCreds, ImportOptions{CertDir: certDir, Passphrase: "passphrase"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	_, err = dst.ImportBundle(bundle, dstCreds, ImportOptions{Name: "prod-eu", CertDir: certDir, Passphrase: "wrong"})
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// Crafted iteration counts are rejected before deriving the key
+	for _, iterations := range []int{0, 1, maxBundleIterations + 1} {
+		tampered := *bundle
+		secrets := *bundle.Secrets
+		secrets.Iterations = iterations
+		tampered.Secrets = &secrets
+		_, err = dst.ImportBundle(&tampered, dstCreds, ImportOptions{Name: "prod-eu", CertDir: certDir, Passphrase: "passphrase"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "iterations")
+	}
+
+	result, err := dst.ImportBundle(bundle, dstCreds, ImportOptions{Name: "prod-eu", CertDir: certDir, Passphrase: "passphrase"})
+	require.NoError(t, err)
+	assert.Equal(t, "prod-eu", result.Profile)
+	assert.True(t, result.Credentials)
+	assert.Equal(t, []string{filepath.Join(certDir, "acme.p12")}, result.Files)
+
+	imported := dst.Profiles["prod-eu"]
+	assert.Equal(t, filepath.Join(certDir, "acme.p12"), imported.P12File)
+	assert.Equal(t, "shop", imported.DefaultNamespace)
+	assert.Equal(t, "p12", imported.AuthMethod)
+	assert.Equal(t, "prod", dst.CurrentProfile)
+	// The identical tenant is shared
+	assert.Len(t, dst.Tenants, 1)
+
+	written, err := os.ReadFile(imported.P12File)
+	require.NoError(t, err)
+	assert.Equal(t, "p12 data", string(written))
+	assert.Equal(t, "hunter2", dstCreds.Profiles["prod-eu"].P12Password)
+	assert.Empty(t, dstCreds.Profiles["prod-eu"].SessionToken)
+
+	// Without secrets the certificate is reported as missing
+	result, err = dst.ImportBundle(plain, nil, ImportOptions{Name: "prod-us", CertDir: filepath.Join(dir, "other")})
+	require.NoError(t, err)
+	assert.False(t, result.Credentials)
+	assert.Equal(t, []string{filepath.Join(dir, "other", "acme.p12")}, result.Missing)
+
+	// Overwriting keeps the current profile
+	_, err = dst.ImportBundle(plain, nil, ImportOptions{Overwrite: true, CertDir: certDir})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", dst.CurrentProfile)
+	assert.Equal(t, "p12", dst.Profiles["prod"].AuthMethod)
+}
@@ -0,0 +1,472 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tenant describes an F5 XC tenant a context can talk to.
+type Tenant struct {
+	Name   string `yaml:"name,omitempty"`
+	APIURL string `yaml:"api-url,omitempty"`
+}
+
+// User describes an identity and how it authenticates. Its secrets are kept
+// in the credentials file under the same name.
+type User struct {
+	AuthMethod string `yaml:"auth-method,omitempty"` // "api-token", "certificate", "p12", "sso", "oidc-federation"
+	CertFile   string `yaml:"cert-file,omitempty"`
+	KeyFile    string `yaml:"key-file,omitempty"`
+	P12File    string `yaml:"p12-file,omitempty"`
+	// CertExpiryWarningDays is how many days before client certificate expiry
+	// to start warning (0 uses the default of 30, negative disables warnings).
+	CertExpiryWarningDays int `yaml:"cert-expiry-warning-days,omitempty"`
+	// TokenExchange exchanges the client certificate for a short-lived API
+	// token on first use and authenticates with the cached token afterwards.
+	TokenExchange bool `yaml:"token-exchange,omitempty"`
+	// OIDC workload identity federation settings (auth-method: oidc-federation).
+	OIDCTokenFile   string `yaml:"oidc-token-file,omitempty"`
+	OIDCTokenEnv    string `yaml:"oidc-token-env,omitempty"`
+	OIDCExchangeURL string `yaml:"oidc-exchange-url,omitempty"`
+	OIDCAudience    string `yaml:"oidc-audience,omitempty"`
+}
+
+// Context combines a tenant, a user and per-context defaults.
+type Context struct {
+	Tenant       string `yaml:"tenant,omitempty"`
+	User         string `yaml:"user,omitempty"`
+	Namespace    string `yaml:"namespace,omitempty"`
+	OutputFormat string `yaml:"output-format,omitempty"`
+	Protected    bool   `yaml:"protected,omitempty"`
+	ReadOnly     bool   `yaml:"read-only,omitempty"`
+}
+
+// configFile is the on-disk layout of Config.
+type configFile struct {
+	APIVersion     string             `yaml:"apiVersion"`
+	CurrentContext string             `yaml:"current-context,omitempty"`
+	Tenants        map[string]Tenant  `yaml:"tenants,omitempty"`
+	Users          map[string]User    `yaml:"users,omitempty"`
+	Contexts       map[string]Context `yaml:"contexts,omitempty"`
+	Aliases        map[string]string  `yaml:"aliases,omitempty"`
+	Defaults       map[string]string  `yaml:"defaults,omitempty"`
+}
+
+// MarshalYAML writes the tenants, users and contexts layout.
+func (c Config) MarshalYAML() (interface{}, error) {
+	c.sync()
+	return configFile{
+		APIVersion:     ConfigAPIVersion,
+		CurrentContext: c.CurrentProfile,
+		Tenants:        c.Tenants,
+		Users:          c.Users,
+		Contexts:       c.Contexts,
+		Aliases:        c.Aliases,
+		Defaults:       c.Defaults,
+	}, nil
+}
+
+// UnmarshalYAML reads the tenants, users and contexts layout. Files in the
+// flat profiles layout of older versions are upgraded by the migrations in
+// migrate.go before they are decoded.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	var file configFile
+	if err := node.Decode(&file); err != nil {
+		return err
+	}
+
+	c.Tenants = file.Tenants
+	c.Users = file.Users
+	c.Contexts = file.Contexts
+	c.Aliases = file.Aliases
+	c.Defaults = file.Defaults
+	c.CurrentProfile = file.CurrentContext
+	c.Profiles = nil
+
+	c.sync()
+	return nil
+}
+
+// sync migrates legacy profiles into contexts when there are none yet and
+// refreshes the resolved Profiles view.
+func (c *Config) sync() {
+	if c.Tenants == nil {
+		c.Tenants = make(map[string]Tenant)
+	}
+	if c.Users == nil {
+		c.Users = make(map[string]User)
+	}
+	if c.Contexts == nil {
+		c.Contexts = make(map[string]Context)
+	}
+
+	if len(c.Contexts) == 0 && len(c.Profiles) > 0 {
+		c.migrateProfiles()
+	}
+
+	c.Profiles = make(map[string]Profile, len(c.Contexts))
+	for name, ctx := range c.Contexts {
+		c.Profiles[name] = c.resolve(ctx)
+	}
+}
+
+// migrateProfiles splits flat profiles into tenants, users and contexts.
+// Each profile becomes a context and a user of the same name, so existing
+// credentials keep working; identical tenants are shared.
+func (c *Config) migrateProfiles() {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := c.Profiles[name]
+
+		var tenantKey string
+		if p.Tenant != "" || p.APIURL != "" {
+			tenantKey = c.addTenant(name, Tenant{Name: p.Tenant, APIURL: p.APIURL})
+		}
+
+		c.Users[name] = p.user()
+
+		c.Contexts[name] = Context{
+			Tenant:       tenantKey,
+			User:         name,
+			Namespace:    p.DefaultNamespace,
+			OutputFormat: p.OutputFormat,
+		}
+	}
+}
+
+// addTenant returns the key of an identical tenant entry, or adds the
+// tenant under its name (or the profile name when that is taken).
+func (c *Config) addTenant(profileName string, tenant Tenant) string {
+	keys := make([]string, 0, len(c.Tenants))
+	for key := range c.Tenants {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if c.Tenants[key] == tenant {
+			return key
+		}
+	}
+
+	key := tenant.Name
+	if _, taken := c.Tenants[key]; key == "" || taken {
+		key = profileName
+	}
+	for i := 2; ; i++ {
+		if _, taken := c.Tenants[key]; !taken {
+			break
+		}
+		key = fmt.Sprintf("%s-%d", profileName, i)
+	}
+
+	c.Tenants[key] = tenant
+	return key
+}
+
+// tenantShared reports whether a context other than name uses the tenant.
+func (c *Config) tenantShared(key, name string) bool {
+	for other, ctx := range c.Contexts {
+		if other != name && ctx.Tenant == key {
+			return true
+		}
+	}
+	return false
+}
+
+// userShared reports whether a context other than name uses the user.
+func (c *Config) userShared(key, name string) bool {
+	for other, ctx := range c.Contexts {
+		if other != name && ctx.User == key {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve flattens a context into the profile the rest of the CLI consumes.
+func (c *Config) resolve(ctx Context) Profile {
+	tenant := c.Tenants[ctx.Tenant]
+
+	profile := Profile{
+		Tenant:           tenant.Name,
+		APIURL:           tenant.APIURL,
+		DefaultNamespace: ctx.Namespace,
+		OutputFormat:     ctx.OutputFormat,
+		Protected:        ctx.Protected,
+		ReadOnly:         ctx.ReadOnly,
+	}
+	profile.setUser(c.Users[ctx.User])
+	return profile
+}
+
+// user returns the authentication settings of the profile.
+func (p Profile) user() User {
+	return User{
+		AuthMethod:            p.AuthMethod,
+		CertFile:              p.CertFile,
+		KeyFile:               p.KeyFile,
+		P12File:               p.P12File,
+		CertExpiryWarningDays: p.CertExpiryWarningDays,
+		TokenExchange:         p.TokenExchange,
+		OIDCTokenFile:         p.OIDCTokenFile,
+		OIDCTokenEnv:          p.OIDCTokenEnv,
+		OIDCExchangeURL:       p.OIDCExchangeURL,
+		OIDCAudience:          p.OIDCAudience,
+	}
+}
+
+// setUser replaces the authentication settings of the profile.
+func (p *Profile) setUser(user User) {
+	p.AuthMethod = user.AuthMethod
+	p.CertFile = user.CertFile
+	p.KeyFile = user.KeyFile
+	p.P12File = user.P12File
+	p.CertExpiryWarningDays = user.CertExpiryWarningDays
+	p.TokenExchange = user.TokenExchange
+	p.OIDCTokenFile = user.OIDCTokenFile
+	p.OIDCTokenEnv = user.OIDCTokenEnv
+	p.OIDCExchangeURL = user.OIDCExchangeURL
+	p.OIDCAudience = user.OIDCAudience
+}
+
+// set sets a key on the profile.
+func (p *Profile) set(key, value string) error {
+	switch key {
+	case "tenant":
+		p.Tenant = value
+	case "api-url":
+		p.APIURL = value
+	case "default-namespace":
+		p.DefaultNamespace = value
+	case "output-format":
+		p.OutputFormat = value
+	case "protected", "read-only":
+		enabled, err := parseBoolSetting(key, value)
+		if err != nil {
+			return err
+		}
+		if key == "protected" {
+			p.Protected = enabled
+		} else {
+			p.ReadOnly = enabled
+		}
+	default:
+		user := p.user()
+		if err := user.set(key, value); err != nil {
+			return err
+		}
+		p.setUser(user)
+	}
+	return nil
+}
+
+// CurrentUser returns the user of the current context. Credentials are
+// stored under this name.
+func (c *Config) CurrentUser() string {
+	c.sync()
+	if ctx, ok := c.Contexts[c.CurrentProfile]; ok && ctx.User != "" {
+		return ctx.User
+	}
+	return c.CurrentProfile
+}
+
+// ContextNames returns the names of all contexts in sorted order.
+func (c *Config) ContextNames() []string {
+	c.sync()
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTenant creates or replaces a tenant entry.
+func (c *Config) SetTenant(name string, tenant Tenant) {
+	c.sync()
+	c.Tenants[name] = tenant
+	c.sync()
+}
+
+// SetUser creates or replaces a user entry.
+func (c *Config) SetUser(name string, user User) {
+	c.sync()
+	c.Users[name] = user
+	c.sync()
+}
+
+// SetContext creates or replaces a context. The tenant and user it
+// references must exist.
+func (c *Config) SetContext(name string, ctx Context) error {
+	if name == "" {
+		return fmt.Errorf("context name is required")
+	}
+
+	c.sync()
+	if _, ok := c.Tenants[ctx.Tenant]; ctx.Tenant != "" && !ok {
+		return fmt.Errorf("tenant %q not found", ctx.Tenant)
+	}
+	if _, ok := c.Users[ctx.User]; ctx.User != "" && !ok {
+		return fmt.Errorf("user %q not found", ctx.User)
+	}
+
+	c.Contexts[name] = ctx
+	c.sync()
+	return nil
+}
+
+// UseContext makes the named context current.
+func (c *Config) UseContext(name string) error {
+	c.sync()
+	if _, ok := c.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	c.CurrentProfile = name
+	return nil
+}
+
+// RenameContext renames a context, following it if it is current.
+func (c *Config) RenameContext(oldName, newName string) error {
+	c.sync()
+	ctx, ok := c.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("context %q not found", oldName)
+	}
+	if newName == "" {
+		return fmt.Errorf("context name is required")
+	}
+	if _, exists := c.Contexts[newName]; exists {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+
+	delete(c.Contexts, oldName)
+	c.Contexts[newName] = ctx
+	if c.CurrentProfile == oldName {
+		c.CurrentProfile = newName
+	}
+
+	c.sync()
+	return nil
+}
+
+// DeleteContext removes a context. Deleting the current context leaves no
+// context selected. Tenants and users are kept since other contexts may
+// still reference them.
+func (c *Config) DeleteContext(name string) error {
+	c.sync()
+	if _, ok := c.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+
+	delete(c.Contexts, name)
+	if c.CurrentProfile == name {
+		c.CurrentProfile = ""
+	}
+
+	// Drop the resolved view so an emptied config is not migrated back
+	c.Profiles = nil
+	c.sync()
+	return nil
+}
+
+// CreateProfile adds an empty profile: a context with a user of the same
+// name. Settings are added with SetProfileKey.
+func (c *Config) CreateProfile(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	c.sync()
+	if _, exists := c.Contexts[name]; exists {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if _, exists := c.Users[name]; exists {
+		return fmt.Errorf("user %q already exists; create a context for it with 'f5xcctl config set-context %s --user %s'", name, name, name)
+	}
+
+	c.Users[name] = User{}
+	c.Contexts[name] = Context{User: name}
+	c.sync()
+	return nil
+}
+
+// CopyProfile copies a profile. The copy shares the source's tenant and gets
+// its own copy of the user, so changing one profile's authentication does
+// not affect the other.
+func (c *Config) CopyProfile(src, dst string) error {
+	if dst == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	c.sync()
+	ctx, ok := c.Contexts[src]
+	if !ok {
+		return fmt.Errorf("profile %q not found", src)
+	}
+	if _, exists := c.Contexts[dst]; exists {
+		return fmt.Errorf("profile %q already exists", dst)
+	}
+	if _, exists := c.Users[dst]; exists {
+		return fmt.Errorf("user %q already exists", dst)
+	}
+
+	c.Users[dst] = c.Users[ctx.User]
+	ctx.User = dst
+	c.Contexts[dst] = ctx
+	c.sync()
+	return nil
+}
+
+// RenameProfile renames a profile. A user named after the profile and used
+// by no other context is renamed with it.
+func (c *Config) RenameProfile(oldName, newName string) error {
+	c.sync()
+	ctx, ok := c.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("profile %q not found", oldName)
+	}
+
+	ownsUser := ctx.User == oldName && !c.userShared(oldName, oldName)
+	if _, exists := c.Users[newName]; ownsUser && exists {
+		return fmt.Errorf("user %q already exists", newName)
+	}
+
+	if err := c.RenameContext(oldName, newName); err != nil {
+		return err
+	}
+
+	if ownsUser {
+		c.Users[newName] = c.Users[oldName]
+		delete(c.Users, oldName)
+		ctx.User = newName
+		c.Contexts[newName] = ctx
+		c.sync()
+	}
+	return nil
+}
+
+// DeleteProfile deletes a profile along with its user and tenant when no
+// other context uses them.
+func (c *Config) DeleteProfile(name string) error {
+	c.sync()
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	if ctx.User != "" && !c.userShared(ctx.User, name) {
+		delete(c.Users, ctx.User)
+	}
+	if ctx.Tenant != "" && !c.tenantShared(ctx.Tenant, name) {
+		delete(c.Tenants, ctx.Tenant)
+	}
+
+	return c.DeleteContext(name)
+}
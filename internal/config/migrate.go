@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// API versions written to the configuration and credentials files.
+const (
+	ConfigAPIVersion      = "f5xcctl/v1"
+	CredentialsAPIVersion = "f5xcctl/v1"
+)
+
+// Migration upgrades a file from one apiVersion to the next. Files written
+// before apiVersion was introduced have an empty version.
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Migrate     func(doc map[string]interface{}) (map[string]interface{}, error)
+}
+
+// configMigrations upgrade the configuration file, in order.
+var configMigrations = []Migration{
+	{
+		From:        "",
+		To:          "f5xcctl/v1",
+		Description: "split flat profiles into tenants, users and contexts",
+		Migrate:     migrateProfilesDocument,
+	},
+}
+
+// credentialsMigrations upgrade the credentials file, in order.
+var credentialsMigrations = []Migration{
+	{
+		From: "",
+		To:   "f5xcctl/v1",
+		// Migrated profiles get a user of the same name, so the keys stay valid
+		Description: "key credentials by user",
+		Migrate: func(doc map[string]interface{}) (map[string]interface{}, error) {
+			return doc, nil
+		},
+	},
+}
+
+// MigrationPlan describes how a file is upgraded to the current apiVersion.
+type MigrationPlan struct {
+	Path        string
+	FromVersion string
+	ToVersion   string
+	Steps       []Migration
+	Original    []byte
+	Migrated    []byte
+}
+
+// Pending reports whether the file needs to be migrated.
+func (p *MigrationPlan) Pending() bool {
+	return len(p.Steps) > 0
+}
+
+// BackupPath returns where the original file is kept when migrating.
+func (p *MigrationPlan) BackupPath() string {
+	version := p.FromVersion
+	if version == "" {
+		version = "legacy"
+	}
+	return fmt.Sprintf("%s.%s.bak", p.Path, strings.ReplaceAll(version, "/", "-"))
+}
+
+// Apply backs up the original file and writes the migrated one in its place.
+func (p *MigrationPlan) Apply() error {
+	if !p.Pending() {
+		return nil
+	}
+	if err := p.backup(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.Path, p.Migrated, 0o600); err != nil {
+		return fmt.Errorf("failed to write migrated file: %w", err)
+	}
+	return nil
+}
+
+// backup writes the original file next to it.
+func (p *MigrationPlan) backup() error {
+	if err := os.WriteFile(p.BackupPath(), p.Original, 0o600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", p.Path, err)
+	}
+	return nil
+}
+
+// PlanConfigMigration returns the migrations pending for a configuration file.
+func PlanConfigMigration(path string) (*MigrationPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return planMigration(path, data, configMigrations, ConfigAPIVersion, canonicalConfig)
+}
+
+// PlanCredentialsMigration returns the migrations pending for a credentials file.
+func PlanCredentialsMigration(path string) (*MigrationPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	return planMigration(path, data, credentialsMigrations, CredentialsAPIVersion, canonicalCredentials)
+}
+
+// planMigration applies migrations to data until it reaches the current
+// version. canonical re-encodes the result the way Save would write it.
+func planMigration(path string, data []byte, migrations []Migration, current string,
+	canonical func(doc map[string]interface{}) ([]byte, error),
+) (*MigrationPlan, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	version, _ := doc["apiVersion"].(string)
+	plan := &MigrationPlan{
+		Path:        path,
+		FromVersion: version,
+		ToVersion:   current,
+		Original:    data,
+		Migrated:    data,
+	}
+
+	for version != current {
+		step := findMigration(migrations, version)
+		if step == nil {
+			return nil, fmt.Errorf("unsupported apiVersion %q in %s (this version of f5xcctl supports %s)", version, path, current)
+		}
+
+		var err error
+		if doc, err = step.Migrate(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s to %s: %w", path, step.To, err)
+		}
+		doc["apiVersion"] = step.To
+		version = step.To
+		plan.Steps = append(plan.Steps, *step)
+	}
+
+	if plan.Pending() {
+		migrated, err := canonical(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode migrated %s: %w", path, err)
+		}
+		plan.Migrated = migrated
+	}
+
+	return plan, nil
+}
+
+func findMigration(migrations []Migration, from string) *Migration {
+	for i := range migrations {
+		if migrations[i].From == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// remarshal converts between a generic document and a typed value.
+func remarshal(in, out interface{}) error {
+	data, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+func canonicalConfig(doc map[string]interface{}) ([]byte, error) {
+	var cfg Config
+	if err := remarshal(doc, &cfg); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&cfg)
+}
+
+func canonicalCredentials(doc map[string]interface{}) ([]byte, error) {
+	var creds Credentials
+	if err := remarshal(doc, &creds); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&creds)
+}
+
+// migrateProfilesDocument converts the flat profiles layout into tenants,
+// users and contexts.
+func migrateProfilesDocument(doc map[string]interface{}) (map[string]interface{}, error) {
+	var legacy struct {
+		CurrentProfile string             `yaml:"current-profile"`
+		Profiles       map[string]Profile `yaml:"profiles"`
+	}
+	if err := remarshal(doc, &legacy); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		CurrentProfile: legacy.CurrentProfile,
+		Profiles:       legacy.Profiles,
+	}
+	cfg.sync()
+
+	var migrated map[string]interface{}
+	if err := remarshal(cfg, &migrated); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the name of the per-project configuration file. It is
+// discovered in the working directory and its parents.
+const ProjectFileName = ".f5xcctl.yaml"
+
+// Project holds per-project settings, typically checked into a GitOps
+// repository. They are layered on top of the global configuration: they
+// take precedence over the profile but not over flags or the environment.
+type Project struct {
+	// Profile selects the profile (context) to use unless --profile is given.
+	Profile string `yaml:"profile,omitempty"`
+	// Namespace is the default namespace of the project.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Selector is the default label selector when listing resources.
+	Selector string `yaml:"selector,omitempty"`
+	// ManifestDirs are applied and diffed when no file is given. Relative
+	// paths are relative to the directory of the project file.
+	ManifestDirs []string `yaml:"manifest-dirs,omitempty"`
+
+	path string // file the project was loaded from
+}
+
+// FindProject looks for a project file in dir and its parents. It returns
+// nil without an error when there is none.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadProject(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProject loads a project file. Unknown keys are rejected so that typos
+// do not silently target the wrong namespace.
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	var project Project
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&project); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse project file %s: %w", path, err)
+	}
+	project.path = path
+
+	return &project, nil
+}
+
+// Path returns the file the project was loaded from.
+func (p *Project) Path() string {
+	return p.path
+}
+
+// ManifestPaths returns the manifest directories as absolute paths.
+func (p *Project) ManifestPaths() []string {
+	paths := make([]string, 0, len(p.ManifestDirs))
+	for _, dir := range p.ManifestDirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(p.path), dir)
+		}
+		paths = append(paths, filepath.Clean(dir))
+	}
+	return paths
+}
+
+// settings returns the profile settings pinned by the project.
+func (p *Project) settings() map[string]string {
+	settings := make(map[string]string)
+	if p.Namespace != "" {
+		settings["default-namespace"] = p.Namespace
+	}
+	return settings
+}
+
+// SetProject layers a project on top of the configuration. ResolveSettings
+// applies its settings between the environment and the current profile.
+func (c *Config) SetProject(p *Project) {
+	c.project = p
+}
+
+// Project returns the project layered on top of the configuration, if any.
+func (c *Config) Project() *Project {
+	return c.project
+}
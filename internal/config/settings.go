@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Origins of an effective setting, from highest to lowest precedence.
+const (
+	OriginFlag    = "flag"
+	OriginEnv     = "env"
+	OriginProject = "project"
+	OriginProfile = "profile"
+	OriginDefault = "default"
+)
+
+// ProfileKeys lists the settings of a profile in display order.
+var ProfileKeys = []string{
+	"tenant",
+	"api-url",
+	"auth-method",
+	"default-namespace",
+	"output-format",
+	"cert-file",
+	"key-file",
+	"p12-file",
+	"cert-expiry-warning-days",
+	"token-exchange",
+	"oidc-token-file",
+	"oidc-token-env",
+	"oidc-exchange-url",
+	"oidc-audience",
+	"protected",
+	"read-only",
+}
+
+// settingEnv maps settings to the environment variables that override them,
+// in order of preference.
+var settingEnv = map[string][]string{
+	"tenant":                   {"F5XC_TENANT"},
+	"api-url":                  {"F5XC_API_URL"},
+	"auth-method":              {"F5XC_AUTH_METHOD"},
+	"default-namespace":        {"F5XC_NAMESPACE", "F5XC_DEFAULT_NAMESPACE"},
+	"output-format":            {"F5XC_OUTPUT", "F5XC_OUTPUT_FORMAT"},
+	"cert-file":                {"F5XC_CERT_FILE"},
+	"key-file":                 {"F5XC_KEY_FILE"},
+	"p12-file":                 {"F5XC_API_P12_FILE", "F5XC_P12_FILE"},
+	"cert-expiry-warning-days": {"F5XC_CERT_EXPIRY_WARNING_DAYS"},
+	"token-exchange":           {"F5XC_TOKEN_EXCHANGE"},
+	"oidc-token-file":          {"F5XC_OIDC_TOKEN_FILE"},
+	"oidc-token-env":           {"F5XC_OIDC_TOKEN_ENV"},
+	"oidc-exchange-url":        {"F5XC_OIDC_EXCHANGE_URL"},
+	"oidc-audience":            {"F5XC_OIDC_AUDIENCE"},
+	"protected":                {"F5XC_PROTECTED"},
+	"read-only":                {"F5XC_READ_ONLY"},
+}
+
+// settingFlags maps settings to the global flags that override them.
+var settingFlags = map[string]string{
+	"tenant":            "tenant",
+	"api-url":           "api-url",
+	"default-namespace": "namespace",
+	"output-format":     "output",
+}
+
+// settingDefaults are used when no other source sets a value.
+var settingDefaults = map[string]string{
+	"auth-method":       "api-token",
+	"default-namespace": "default",
+	"output-format":     "table",
+}
+
+// Setting is the effective value of a profile setting.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+	// Source names the flag, environment variable or profile that set the value.
+	Source string `json:"source,omitempty"`
+}
+
+// ResolveSettings computes the effective value of every profile setting with
+// the precedence flag > environment > project > profile > default, and
+// applies flag, environment and project values to cfg as overrides of its
+// current profile. Only flags in flags that were set on the command line are
+// considered.
+//
+// When no auth-method is set by a flag or environment variable but the
+// environment carries credentials for a method (F5XC_API_P12_FILE,
+// F5XC_CERT_FILE and F5XC_KEY_FILE, F5XC_OIDC_EXCHANGE_URL or
+// F5XC_API_TOKEN, in that order), that method is used.
+//
+// The environment can turn on protected and read-only, but never turn them
+// off for a profile that has them; that takes an explicit
+// 'f5xcctl config unset'.
+func ResolveSettings(cfg *Config, flags *pflag.FlagSet) ([]Setting, error) {
+	v := viper.New()
+
+	project := make(map[string]string)
+	if cfg.project != nil {
+		project = cfg.project.settings()
+	}
+
+	stored := make(map[string]interface{})
+	for _, key := range ProfileKeys {
+		if value, err := cfg.GetProfileKey(cfg.CurrentProfile, key); err == nil && !isZeroSetting(key, value) {
+			stored[key] = value
+		}
+	}
+	for key, value := range project {
+		stored[key] = value
+	}
+	if err := v.MergeConfigMap(stored); err != nil {
+		return nil, fmt.Errorf("failed to read profile settings: %w", err)
+	}
+
+	for _, key := range ProfileKeys {
+		if err := v.BindEnv(append([]string{key}, settingEnv[key]...)...); err != nil {
+			return nil, err
+		}
+		if def, ok := settingDefaults[key]; ok {
+			v.SetDefault(key, def)
+		}
+		if flag := changedFlag(flags, key); flag != nil {
+			if err := v.BindPFlag(key, flag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	settings := make([]Setting, 0, len(ProfileKeys))
+	for _, key := range ProfileKeys {
+		setting := Setting{Key: key, Value: v.GetString(key)}
+
+		switch {
+		case changedFlag(flags, key) != nil:
+			setting.Origin, setting.Source = OriginFlag, "--"+settingFlags[key]
+		case lookupEnv(key) != "":
+			setting.Origin, setting.Source = OriginEnv, lookupEnv(key)
+		case project[key] != "":
+			setting.Origin, setting.Source = OriginProject, cfg.project.Path()
+		case stored[key] != nil:
+			setting.Origin, setting.Source = OriginProfile, cfg.CurrentProfile
+		case settingDefaults[key] != "":
+			setting.Origin = OriginDefault
+		default:
+			continue
+		}
+
+		settings = append(settings, setting)
+	}
+
+	for i, setting := range settings {
+		if !isGuardSetting(setting.Key) || setting.Origin != OriginEnv || stored[setting.Key] == nil {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(setting.Value); err == nil && !enabled {
+			settings[i] = Setting{Key: setting.Key, Value: fmt.Sprint(stored[setting.Key]), Origin: OriginProfile, Source: cfg.CurrentProfile}
+		}
+	}
+
+	if method, envVar := inferAuthMethod(); method != "" {
+		for i := range settings {
+			if settings[i].Key == "auth-method" && settings[i].Origin != OriginFlag && settings[i].Origin != OriginEnv {
+				settings[i].Value = method
+				settings[i].Origin = OriginEnv
+				settings[i].Source = "inferred from " + envVar
+			}
+		}
+	}
+
+	for _, setting := range settings {
+		if setting.Origin == OriginProfile || setting.Origin == OriginDefault {
+			continue
+		}
+		if err := cfg.Override(setting.Key, setting.Value); err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %w", setting.Origin, setting.Source, err)
+		}
+	}
+
+	return settings, nil
+}
+
+// changedFlag returns the flag that overrides key if it was set.
+func changedFlag(flags *pflag.FlagSet, key string) *pflag.Flag {
+	name, ok := settingFlags[key]
+	if !ok || flags == nil {
+		return nil
+	}
+	if flag := flags.Lookup(name); flag != nil && flag.Changed {
+		return flag
+	}
+	return nil
+}
+
+// lookupEnv returns the first environment variable that sets key.
+func lookupEnv(key string) string {
+	for _, name := range settingEnv[key] {
+		if os.Getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// inferAuthMethod returns the auth method implied by credentials in the
+// environment, unless F5XC_AUTH_METHOD chooses one explicitly.
+func inferAuthMethod() (method, envVar string) {
+	switch {
+	case lookupEnv("auth-method") != "":
+		return "", ""
+	case lookupEnv("p12-file") != "":
+		return "p12", lookupEnv("p12-file")
+	case os.Getenv("F5XC_CERT_FILE") != "" && os.Getenv("F5XC_KEY_FILE") != "":
+		return "certificate", "F5XC_CERT_FILE"
+	case os.Getenv("F5XC_OIDC_EXCHANGE_URL") != "":
+		return "oidc-federation", "F5XC_OIDC_EXCHANGE_URL"
+	case os.Getenv("F5XC_API_TOKEN") != "":
+		return "api-token", "F5XC_API_TOKEN"
+	default:
+		return "", ""
+	}
+}
+
+// isGuardSetting reports whether key is one of the settings that guard a
+// profile against changes.
+func isGuardSetting(key string) bool {
+	return key == "protected" || key == "read-only"
+}
+
+// isZeroSetting reports whether a stored value means "not set".
+func isZeroSetting(key, value string) bool {
+	switch key {
+	case "cert-expiry-warning-days":
+		return value == "0"
+	case "token-exchange", "protected", "read-only":
+		return value == "false"
+	default:
+		return strings.TrimSpace(value) == ""
+	}
+}
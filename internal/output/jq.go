@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+// JQFormatter formats output using a jq filter, evaluated by an embedded jq
+// implementation. Every result of the filter is printed as indented JSON,
+// like jq does by default.
+type JQFormatter struct {
+	Expression string
+	// RawOutput prints string results without quotes, like jq --raw-output
+	RawOutput bool
+}
+
+// Format formats data using the jq filter.
+func (f *JQFormatter) Format(w io.Writer, data interface{}) error {
+	if f.Expression == "" {
+		return fmt.Errorf("jq expression is required")
+	}
+
+	query, err := gojq.Parse(f.Expression)
+	if err != nil {
+		return fmt.Errorf("failed to parse jq expression: %w", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return fmt.Errorf("failed to compile jq expression: %w", err)
+	}
+
+	// gojq only works on the generic form produced by decoding JSON
+	input, err := normalizeJSON(data)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	iter := code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			// halt ends the program without an error
+			var haltErr *gojq.HaltError
+			if errors.As(err, &haltErr) && haltErr.Value() == nil {
+				break
+			}
+			return fmt.Errorf("failed to evaluate jq expression: %w", err)
+		}
+		if err := f.writeValue(&buf, v); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeValue writes a single result of the filter.
+func (f *JQFormatter) writeValue(buf *bytes.Buffer, v interface{}) error {
+	if s, ok := v.(string); ok && f.RawOutput {
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+		return nil
+	}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to marshal jq result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,719 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled JSONPath template in the syntax kubectl uses: text
+// with {expression} blocks, {range expression}...{end} loops and {"text"}
+// literals. An expression selects values with
+//
+//	.field or ['field']        a field (* selects all fields)
+//	['a','b']                  several fields
+//	[0], [-1], [0,2]           array elements
+//	[1:3], [::2]               array slices
+//	[*]                        all elements
+//	[?(@.field == "value")]    elements matching a filter (==, !=, <, <=, >, >=)
+//	[?(@.field)]               elements that have a field
+//	..field                    a field at any depth
+//
+// Expressions are relative to the current value: the data, or the element
+// of the enclosing range. $ refers to the data. Templates without braces are
+// read as a single expression, so ".metadata.name" and "{.metadata.name}"
+// are the same.
+type JSONPath struct {
+	nodes []templateNode
+}
+
+// templateNode is literal text, an expression or a range loop.
+type templateNode struct {
+	text    string
+	expr    *pathExpr
+	isRange bool
+	body    []templateNode
+}
+
+// CompileJSONPath compiles a JSONPath template.
+func CompileJSONPath(template string) (*JSONPath, error) {
+	if !strings.Contains(template, "{") {
+		expr, err := parsePathExpr(strings.TrimSpace(template))
+		if err != nil {
+			return nil, err
+		}
+		return &JSONPath{nodes: []templateNode{{expr: expr}}}, nil
+	}
+
+	pos := 0
+	nodes, err := parseTemplate(template, &pos, false)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{nodes: nodes}, nil
+}
+
+// CompileJSONPathExpression compiles a template that consists of a single
+// expression, such as a sort key or a custom column.
+func CompileJSONPathExpression(expr string) (*JSONPath, error) {
+	j, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(j.nodes) != 1 || j.nodes[0].expr == nil || j.nodes[0].isRange {
+		return nil, fmt.Errorf("invalid jsonpath %q: expected a single expression", expr)
+	}
+	return j, nil
+}
+
+// EvaluateJSONPath evaluates a single JSONPath expression against data
+// decoded from JSON. Expressions that select at most one value return it,
+// or nil; others, such as .items[*].name, return all selected values.
+func EvaluateJSONPath(data interface{}, expr string) interface{} {
+	j, err := CompileJSONPathExpression(expr)
+	if err != nil {
+		return nil
+	}
+	return j.Evaluate(data)
+}
+
+// FindResults returns the values selected by a single-expression template.
+func (j *JSONPath) FindResults(data interface{}) []interface{} {
+	if len(j.nodes) != 1 || j.nodes[0].expr == nil {
+		return nil
+	}
+	return j.nodes[0].expr.eval(data, data)
+}
+
+// Evaluate returns the value selected by a single-expression template, see
+// EvaluateJSONPath.
+func (j *JSONPath) Evaluate(data interface{}) interface{} {
+	results := j.FindResults(data)
+	if len(j.nodes) == 1 && j.nodes[0].expr != nil && j.nodes[0].expr.definite() {
+		if len(results) == 0 {
+			return nil
+		}
+		return results[0]
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
+
+// Execute writes the template applied to data to w. Several values selected
+// by one expression are separated by spaces.
+func (j *JSONPath) Execute(w io.Writer, data interface{}) error {
+	var buf bytes.Buffer
+	executeTemplate(&buf, j.nodes, data, data)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func executeTemplate(buf *bytes.Buffer, nodes []templateNode, root, cur interface{}) {
+	for _, node := range nodes {
+		switch {
+		case node.isRange:
+			results := node.expr.eval(root, cur)
+			// {range .items} iterates like {range .items[*]}
+			if len(results) == 1 {
+				if arr, ok := results[0].([]interface{}); ok {
+					results = arr
+				}
+			}
+			for _, result := range results {
+				executeTemplate(buf, node.body, root, result)
+			}
+		case node.expr != nil:
+			for i, result := range node.expr.eval(root, cur) {
+				if i > 0 {
+					buf.WriteByte(' ')
+				}
+				buf.WriteString(FormatJSONPathValue(result))
+			}
+		default:
+			buf.WriteString(node.text)
+		}
+	}
+}
+
+// FormatJSONPathValue formats a value selected by a JSONPath expression:
+// strings as they are, objects and arrays as JSON.
+func FormatJSONPathValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// parseTemplate parses template nodes from s[*pos:] up to the end of s, or
+// up to {end} when inRange is set.
+func parseTemplate(s string, pos *int, inRange bool) ([]templateNode, error) {
+	var nodes []templateNode
+	for *pos < len(s) {
+		open := strings.IndexByte(s[*pos:], '{')
+		if open < 0 {
+			nodes = append(nodes, templateNode{text: s[*pos:]})
+			*pos = len(s)
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, templateNode{text: s[*pos : *pos+open]})
+		}
+		start := *pos + open
+
+		end, err := closingDelimiter(s, start, '{', '}')
+		if err != nil {
+			return nil, err
+		}
+		block := strings.TrimSpace(s[start+1 : end])
+		*pos = end + 1
+
+		switch {
+		case block == "end":
+			if !inRange {
+				return nil, fmt.Errorf("invalid jsonpath %q: {end} without {range}", s)
+			}
+			return nodes, nil
+		case strings.HasPrefix(block, "range "):
+			expr, err := parsePathExpr(strings.TrimSpace(strings.TrimPrefix(block, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			body, err := parseTemplate(s, pos, true)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, templateNode{expr: expr, isRange: true, body: body})
+		case strings.HasPrefix(block, `"`):
+			text, err := strconv.Unquote(block)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonpath string %s: %w", block, err)
+			}
+			nodes = append(nodes, templateNode{text: text})
+		case strings.HasPrefix(block, "'") && strings.HasSuffix(block, "'") && len(block) > 1:
+			nodes = append(nodes, templateNode{text: block[1 : len(block)-1]})
+		default:
+			expr, err := parsePathExpr(block)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, templateNode{expr: expr})
+		}
+	}
+
+	if inRange {
+		return nil, fmt.Errorf("invalid jsonpath %q: {range} without {end}", s)
+	}
+	return nodes, nil
+}
+
+// closingDelimiter returns the index of the delimiter that closes the one
+// at s[start], skipping quoted strings and nested delimiters.
+func closingDelimiter(s string, start int, open, closing byte) (int, error) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == open:
+			depth++
+		case c == closing:
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid jsonpath %q: unclosed %c", s, open)
+}
+
+// stepKind is the kind of a step of a path expression.
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepWildcard
+	stepRecurse
+	stepIndex
+	stepSlice
+	stepFilter
+)
+
+// pathStep selects values from each value produced by the previous step.
+type pathStep struct {
+	kind    stepKind
+	keys    []string    // stepField
+	indices []int       // stepIndex
+	slice   [3]*int     // stepSlice: start, end, step
+	filter  *pathFilter // stepFilter
+}
+
+// pathExpr is a parsed path expression.
+type pathExpr struct {
+	fromRoot bool
+	steps    []pathStep
+}
+
+// pathFilter is a [?(...)] filter. Without an operator it tests whether
+// left selects anything.
+type pathFilter struct {
+	left  *pathExpr
+	op    string
+	right *pathExpr   // a path, or
+	value interface{} // a literal
+}
+
+// definite reports whether the expression selects at most one value.
+func (p *pathExpr) definite() bool {
+	for _, st := range p.steps {
+		switch st.kind {
+		case stepWildcard, stepRecurse, stepSlice, stepFilter:
+			return false
+		case stepField:
+			if len(st.keys) > 1 {
+				return false
+			}
+		case stepIndex:
+			if len(st.indices) > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parsePathExpr parses an expression such as .items[*].metadata.name.
+func parsePathExpr(s string) (*pathExpr, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("invalid jsonpath %q: %s", s, fmt.Sprintf(format, args...))
+	}
+
+	expr := &pathExpr{}
+	i := 0
+	if strings.HasPrefix(s, "$") {
+		expr.fromRoot = true
+		i++
+	} else if strings.HasPrefix(s, "@") {
+		i++
+	}
+
+	for i < len(s) {
+		switch {
+		case strings.HasPrefix(s[i:], ".."):
+			expr.steps = append(expr.steps, pathStep{kind: stepRecurse})
+			i += 2
+			if i < len(s) && s[i] != '[' {
+				name, n := readFieldName(s[i:])
+				if name == "" {
+					return nil, invalid("expected a field name after ..")
+				}
+				expr.steps = append(expr.steps, fieldStep(name))
+				i += n
+			}
+		case s[i] == '.':
+			i++
+			if i == len(s) || s[i] == '[' {
+				continue
+			}
+			name, n := readFieldName(s[i:])
+			if name == "" {
+				return nil, invalid("expected a field name at position %d", i)
+			}
+			expr.steps = append(expr.steps, fieldStep(name))
+			i += n
+		case s[i] == '[':
+			end, err := closingDelimiter(s, i, '[', ']')
+			if err != nil {
+				return nil, err
+			}
+			step, err := parseBracket(strings.TrimSpace(s[i+1 : end]))
+			if err != nil {
+				return nil, invalid("%v", err)
+			}
+			expr.steps = append(expr.steps, step)
+			i = end + 1
+		case i == 0:
+			// Relaxed form without a leading dot: metadata.name
+			name, n := readFieldName(s)
+			if name == "" {
+				return nil, invalid("unexpected %q", s[i])
+			}
+			expr.steps = append(expr.steps, fieldStep(name))
+			i += n
+		default:
+			return nil, invalid("unexpected %q at position %d", s[i], i)
+		}
+	}
+	return expr, nil
+}
+
+// readFieldName reads a field name up to the next step.
+func readFieldName(s string) (string, int) {
+	n := strings.IndexAny(s, ".[ \t")
+	if n < 0 {
+		n = len(s)
+	}
+	return s[:n], n
+}
+
+func fieldStep(name string) pathStep {
+	if name == "*" {
+		return pathStep{kind: stepWildcard}
+	}
+	return pathStep{kind: stepField, keys: []string{name}}
+}
+
+// parseBracket parses the content of a [...] step.
+func parseBracket(s string) (pathStep, error) {
+	switch {
+	case s == "*":
+		return pathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		filter, err := parseFilter(strings.TrimSpace(s[2 : len(s)-1]))
+		if err != nil {
+			return pathStep{}, err
+		}
+		return pathStep{kind: stepFilter, filter: filter}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		var keys []string
+		for _, part := range splitTopLevel(s, ',') {
+			key, err := unquote(strings.TrimSpace(part))
+			if err != nil {
+				return pathStep{}, err
+			}
+			keys = append(keys, key)
+		}
+		return pathStep{kind: stepField, keys: keys}, nil
+	case strings.Contains(s, ":"):
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return pathStep{}, fmt.Errorf("invalid slice [%s]", s)
+		}
+		step := pathStep{kind: stepSlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return pathStep{}, fmt.Errorf("invalid slice [%s]", s)
+			}
+			step.slice[i] = &n
+		}
+		if step.slice[2] != nil && *step.slice[2] <= 0 {
+			return pathStep{}, fmt.Errorf("slice step must be positive in [%s]", s)
+		}
+		return step, nil
+	default:
+		step := pathStep{kind: stepIndex}
+		for _, part := range strings.Split(s, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return pathStep{}, fmt.Errorf("invalid index [%s]", s)
+			}
+			step.indices = append(step.indices, n)
+		}
+		return step, nil
+	}
+}
+
+// filterOperators are the comparison operators of filters, longest first.
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses the content of a [?(...)] filter.
+func parseFilter(s string) (*pathFilter, error) {
+	left, op, right := s, "", ""
+	for i := 0; i < len(s) && op == ""; i++ {
+		if s[i] == '"' || s[i] == '\'' {
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in filter %q", s)
+			}
+			i += end + 1
+			continue
+		}
+		for _, candidate := range filterOperators {
+			if strings.HasPrefix(s[i:], candidate) {
+				left, op, right = strings.TrimSpace(s[:i]), candidate, strings.TrimSpace(s[i+len(candidate):])
+				break
+			}
+		}
+	}
+
+	if !strings.HasPrefix(left, "@") && !strings.HasPrefix(left, "$") {
+		return nil, fmt.Errorf("filter %q must start with @", s)
+	}
+	leftExpr, err := parsePathExpr(left)
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{left: leftExpr, op: op}
+	if op == "" {
+		return filter, nil
+	}
+
+	switch {
+	case right == "":
+		return nil, fmt.Errorf("missing value in filter %q", s)
+	case strings.HasPrefix(right, "@") || strings.HasPrefix(right, "$"):
+		if filter.right, err = parsePathExpr(right); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(right, "'") || strings.HasPrefix(right, `"`):
+		if filter.value, err = unquote(right); err != nil {
+			return nil, err
+		}
+	case right == "true" || right == "false":
+		filter.value = right == "true"
+	default:
+		n, err := strconv.ParseFloat(right, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q in filter %q", right, s)
+		}
+		filter.value = n
+	}
+	return filter, nil
+}
+
+// unquote removes the single or double quotes around s.
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return "", fmt.Errorf("expected a quoted string, got %s", s)
+}
+
+// splitTopLevel splits s at sep outside of quotes and brackets.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(' || c == '{':
+			depth++
+		case c == ']' || c == ')' || c == '}':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// eval returns the values the expression selects from cur.
+func (p *pathExpr) eval(root, cur interface{}) []interface{} {
+	values := []interface{}{cur}
+	if p.fromRoot {
+		values = []interface{}{root}
+	}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			next = append(next, st.apply(root, v)...)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+	return values
+}
+
+// apply returns the values the step selects from v.
+func (st pathStep) apply(root, v interface{}) []interface{} {
+	switch st.kind {
+	case stepField:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var values []interface{}
+		for _, key := range st.keys {
+			if value, ok := m[key]; ok && value != nil {
+				values = append(values, value)
+			}
+		}
+		return values
+	case stepWildcard:
+		return children(v)
+	case stepRecurse:
+		return descendants(v, nil)
+	case stepIndex:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+		var values []interface{}
+		for _, idx := range st.indices {
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx >= 0 && idx < len(arr) {
+				values = append(values, arr[idx])
+			}
+		}
+		return values
+	case stepSlice:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+		start, end, step := sliceBound(st.slice[0], 0, len(arr)), sliceBound(st.slice[1], len(arr), len(arr)), 1
+		if st.slice[2] != nil {
+			step = *st.slice[2]
+		}
+		var values []interface{}
+		for i := start; i < end; i += step {
+			values = append(values, arr[i])
+		}
+		return values
+	case stepFilter:
+		var values []interface{}
+		for _, elem := range children(v) {
+			if st.filter.matches(root, elem) {
+				values = append(values, elem)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// sliceBound resolves a slice bound, counting negative ones from the end.
+func sliceBound(bound *int, def, length int) int {
+	if bound == nil {
+		return def
+	}
+	n := *bound
+	if n < 0 {
+		n += length
+	}
+	return max(0, min(n, length))
+}
+
+// children returns the elements of an array or the values of an object, in
+// key order.
+func children(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		return val
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			if val[k] != nil {
+				values = append(values, val[k])
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants appends v and everything below it to values, depth first.
+func descendants(v interface{}, values []interface{}) []interface{} {
+	values = append(values, v)
+	for _, child := range children(v) {
+		values = descendants(child, values)
+	}
+	return values
+}
+
+// matches reports whether elem passes the filter.
+func (f *pathFilter) matches(root, elem interface{}) bool {
+	lefts := f.left.eval(root, elem)
+	if f.op == "" {
+		return len(lefts) > 0
+	}
+	if len(lefts) == 0 {
+		return false
+	}
+
+	right := f.value
+	if f.right != nil {
+		rights := f.right.eval(root, elem)
+		if len(rights) == 0 {
+			return false
+		}
+		right = rights[0]
+	}
+	return compareJSONPathValues(lefts[0], f.op, right)
+}
+
+// compareJSONPathValues compares numbers numerically and everything else as
+// formatted strings.
+func compareJSONPathValues(a interface{}, op string, b interface{}) bool {
+	cmp := 0
+	na, aNum := a.(float64)
+	nb, bNum := b.(float64)
+	if aNum && bNum {
+		switch {
+		case na < nb:
+			cmp = -1
+		case na > nb:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(FormatJSONPathValue(a), FormatJSONPathValue(b))
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
@@ -8,9 +8,7 @@ import (
 	"io"
 	"os"
 	"reflect"
-	"strconv"
 	"strings"
-	"text/tabwriter"
 	"text/template"
 
 	"gopkg.in/yaml.v3"
@@ -24,23 +22,28 @@ type Formatter interface {
 
 // NewFormatter creates a formatter for the specified format.
 func NewFormatter(format string) Formatter {
-	format = strings.ToLower(format)
+	// Only the format name is case-insensitive; expressions and templates
+	// are taken as they are
+	name, arg, hasArg := strings.Cut(format, "=")
+	name = strings.ToLower(name)
 
 	// Handle parameterized formats
-	if strings.HasPrefix(format, "jsonpath=") {
-		expr := strings.TrimPrefix(format, "jsonpath=")
-		return &JSONPathFormatter{Expression: expr}
-	}
-	if strings.HasPrefix(format, "custom-columns=") {
-		spec := strings.TrimPrefix(format, "custom-columns=")
-		return &CustomColumnsFormatter{Spec: spec}
-	}
-	if strings.HasPrefix(format, "go-template=") {
-		tmpl := strings.TrimPrefix(format, "go-template=")
-		return &GoTemplateFormatter{Template: tmpl}
+	if hasArg {
+		switch name {
+		case "jsonpath":
+			return &JSONPathFormatter{Expression: arg}
+		case "custom-columns":
+			return &CustomColumnsFormatter{Spec: arg}
+		case "go-template":
+			return &GoTemplateFormatter{Template: arg}
+		case "jq":
+			return &JQFormatter{Expression: arg}
+		case StyleCSV, StyleTSV, StyleMarkdown:
+			return &TabularFormatter{Style: name, Spec: arg}
+		}
 	}
 
-	switch format {
+	switch name {
 	case "json":
 		return &JSONFormatter{Indent: true}
 	case "yaml":
@@ -49,6 +52,10 @@ func NewFormatter(format string) Formatter {
 		return &TextFormatter{}
 	case "jsonpath":
 		return &JSONPathFormatter{Expression: ""}
+	case "jq":
+		return &JQFormatter{Expression: ""}
+	case StyleCSV, StyleTSV, StyleMarkdown:
+		return &TabularFormatter{Style: name}
 	default:
 		return &TableFormatter{}
 	}
@@ -115,9 +122,6 @@ type TableFormatter struct {
 
 // Format formats data as a table.
 func (f *TableFormatter) Format(w io.Writer, data interface{}) error {
-	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	defer tw.Flush()
-
 	// Handle different data types
 	val := reflect.ValueOf(data)
 	if val.Kind() == reflect.Ptr {
@@ -126,70 +130,19 @@ func (f *TableFormatter) Format(w io.Writer, data interface{}) error {
 
 	switch val.Kind() {
 	case reflect.Slice, reflect.Array:
-		return f.formatSlice(tw, val)
-	case reflect.Struct:
-		return f.formatStruct(tw, val)
-	case reflect.Map:
-		return f.formatMap(tw, val)
+		if val.Len() == 0 {
+			fmt.Fprintln(w, "No resources found")
+			return nil
+		}
+	case reflect.Struct, reflect.Map:
 	default:
 		// For simple types, just print
 		fmt.Fprintln(w, data)
 		return nil
 	}
-}
-
-func (f *TableFormatter) formatSlice(tw *tabwriter.Writer, val reflect.Value) error {
-	if val.Len() == 0 {
-		fmt.Println("No resources found")
-		return nil
-	}
-
-	// Get headers from first element
-	first := val.Index(0)
-	if first.Kind() == reflect.Ptr {
-		first = first.Elem()
-	}
-
-	headers, keys := f.getHeadersAndKeys(first)
 
-	// Print header
-	fmt.Fprintln(tw, strings.Join(headers, "\t"))
-
-	// Print rows
-	for i := 0; i < val.Len(); i++ {
-		elem := val.Index(i)
-		if elem.Kind() == reflect.Ptr {
-			elem = elem.Elem()
-		}
-		row := f.extractRow(elem, keys)
-		fmt.Fprintln(tw, strings.Join(row, "\t"))
-	}
-
-	return nil
-}
-
-func (f *TableFormatter) formatStruct(tw *tabwriter.Writer, val reflect.Value) error {
-	headers, keys := f.getHeadersAndKeys(val)
-
-	// Print header
-	fmt.Fprintln(tw, strings.Join(headers, "\t"))
-
-	// Print row
-	row := f.extractRow(val, keys)
-	fmt.Fprintln(tw, strings.Join(row, "\t"))
-
-	return nil
-}
-
-func (f *TableFormatter) formatMap(tw *tabwriter.Writer, val reflect.Value) error {
-	fmt.Fprintln(tw, "KEY\tVALUE")
-
-	for _, key := range val.MapKeys() {
-		value := val.MapIndex(key)
-		fmt.Fprintf(tw, "%v\t%v\n", key.Interface(), value.Interface())
-	}
-
-	return nil
+	headers, rows := f.content(data)
+	return PrintAligned(w, headers, rows)
 }
 
 func (f *TableFormatter) getHeadersAndKeys(val reflect.Value) ([]string, []string) {
@@ -321,12 +274,15 @@ type NamespaceOutput struct {
 
 // Errorf prints an error message to stderr.
 func Errorf(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	fmt.Fprintf(os.Stderr, Colorize(os.Stderr, Red, "Error:")+" "+format+"\n", args...)
 }
 
-// Successf prints a success message.
+// Successf prints a success message, marked with a check mark on terminals.
 func Successf(format string, args ...interface{}) {
-	fmt.Printf("✓ "+format+"\n", args...)
+	if IsTerminal(os.Stdout) {
+		format = Colorize(os.Stdout, Green, "✓") + " " + format
+	}
+	fmt.Printf(format+"\n", args...)
 }
 
 // Infof prints an info message.
@@ -336,7 +292,7 @@ func Infof(format string, args ...interface{}) {
 
 // Warningf prints a warning message.
 func Warningf(format string, args ...interface{}) {
-	fmt.Printf("Warning: "+format+"\n", args...)
+	fmt.Printf(Colorize(os.Stdout, Yellow, "Warning:")+" "+format+"\n", args...)
 }
 
 // ============================================================================
@@ -354,205 +310,41 @@ func (f *JSONPathFormatter) Format(w io.Writer, data interface{}) error {
 		return fmt.Errorf("jsonpath expression is required")
 	}
 
-	// Clean expression
-	expr := strings.Trim(f.Expression, "'\"")
-	expr = strings.TrimPrefix(expr, "{")
-	expr = strings.TrimSuffix(expr, "}")
-
-	// Convert data to map for easier traversal
-	jsonData, err := json.Marshal(data)
+	j, err := CompileJSONPath(f.Expression)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
-	}
-
-	var dataMap interface{}
-	if err := json.Unmarshal(jsonData, &dataMap); err != nil {
-		return fmt.Errorf("failed to unmarshal data: %w", err)
-	}
-
-	result := evaluateJSONPath(dataMap, expr)
-
-	// Format output
-	switch v := result.(type) {
-	case []interface{}:
-		for _, item := range v {
-			fmt.Fprintln(w, formatJSONPathValue(item))
-		}
-	case nil:
-		// No output for nil
-	default:
-		fmt.Fprintln(w, formatJSONPathValue(v))
-	}
-
-	return nil
-}
-
-// evaluateJSONPath evaluates a JSONPath-like expression
-// Supports: .field, .field.subfield, .items[*].name, .items[0].
-func evaluateJSONPath(data interface{}, expr string) interface{} {
-	if expr == "" || expr == "." {
-		return data
+		return err
 	}
 
-	// Remove leading dot
-	expr = strings.TrimPrefix(expr, ".")
-
-	// Split by dots (handling array notation)
-	parts := splitJSONPathParts(expr)
-
-	current := data
-	for _, part := range parts {
-		if current == nil {
-			return nil
-		}
-
-		// Check for array indexing
-		if strings.Contains(part, "[") {
-			arrayPart, indexStr := parseArrayPart(part)
-
-			// Get the array first
-			if arrayPart != "" {
-				current = getField(current, arrayPart)
-				if current == nil {
-					return nil
-				}
-			}
-
-			// Handle array index
-			arr, ok := current.([]interface{})
-			if !ok {
-				return nil
-			}
-
-			if indexStr == "*" {
-				// Wildcard - return all elements
-				// Process remaining path for each element
-				return current
-			}
-
-			idx, err := strconv.Atoi(indexStr)
-			if err != nil {
-				return nil
-			}
-
-			if idx < 0 || idx >= len(arr) {
-				return nil
-			}
-			current = arr[idx]
-		} else {
-			current = getField(current, part)
-		}
-	}
-
-	// Handle wildcard expansion
-	if arr, ok := current.([]interface{}); ok {
-		// Check if we need to extract a field from each element
-		remaining := findRemainingPath(expr)
-		if remaining != "" {
-			var results []interface{}
-			for _, item := range arr {
-				result := evaluateJSONPath(item, remaining)
-				if result != nil {
-					results = append(results, result)
-				}
-			}
-			return results
-		}
-	}
-
-	return current
-}
-
-// splitJSONPathParts splits a JSONPath expression into parts.
-func splitJSONPathParts(expr string) []string {
-	var parts []string
-	var current strings.Builder
-	bracketDepth := 0
-
-	for _, ch := range expr {
-		switch ch {
-		case '[':
-			bracketDepth++
-			current.WriteRune(ch)
-		case ']':
-			bracketDepth--
-			current.WriteRune(ch)
-		case '.':
-			if bracketDepth == 0 {
-				if current.Len() > 0 {
-					parts = append(parts, current.String())
-					current.Reset()
-				}
-			} else {
-				current.WriteRune(ch)
-			}
-		default:
-			current.WriteRune(ch)
-		}
-	}
-
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
+	dataMap, err := normalizeJSON(data)
+	if err != nil {
+		return err
 	}
 
-	return parts
-}
-
-// parseArrayPart parses "items[0]" into ("items", "0").
-func parseArrayPart(part string) (string, string) {
-	idx := strings.Index(part, "[")
-	if idx == -1 {
-		return part, ""
+	var buf bytes.Buffer
+	if err := j.Execute(&buf, dataMap); err != nil {
+		return err
 	}
-
-	arrayName := part[:idx]
-	indexStr := strings.Trim(part[idx:], "[]")
-	return arrayName, indexStr
-}
-
-// getField gets a field from a map or struct.
-func getField(data interface{}, field string) interface{} {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		return v[field]
-	case map[interface{}]interface{}:
-		return v[field]
-	default:
-		// Use reflection for structs
-		val := reflect.ValueOf(data)
-		if val.Kind() == reflect.Ptr {
-			val = val.Elem()
-		}
-		if val.Kind() == reflect.Struct {
-			f := val.FieldByName(field)
-			if f.IsValid() {
-				return f.Interface()
-			}
-		}
-		return nil
+	// End the output with a newline unless the template does
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
 	}
+	_, err = w.Write(buf.Bytes())
+	return err
 }
 
-// findRemainingPath finds the path after [*].
-func findRemainingPath(expr string) string {
-	idx := strings.Index(expr, "[*]")
-	if idx == -1 {
-		return ""
+// normalizeJSON converts data to the generic form produced by decoding JSON,
+// which is what JSONPath expressions are evaluated against.
+func normalizeJSON(data interface{}) (interface{}, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
 	}
-	remaining := expr[idx+3:]
-	return strings.TrimPrefix(remaining, ".")
-}
 
-// formatJSONPathValue formats a value for JSONPath output.
-func formatJSONPathValue(v interface{}) string {
-	switch val := v.(type) {
-	case string:
-		return val
-	case nil:
-		return ""
-	default:
-		return fmt.Sprintf("%v", val)
+	var normalized interface{}
+	if err := json.Unmarshal(jsonData, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
 	}
+	return normalized, nil
 }
 
 // ============================================================================
@@ -568,6 +360,8 @@ type CustomColumnsFormatter struct {
 type ColumnSpec struct {
 	Header   string
 	JSONPath string
+
+	path *JSONPath
 }
 
 // Format formats data using custom columns.
@@ -576,81 +370,88 @@ func (f *CustomColumnsFormatter) Format(w io.Writer, data interface{}) error {
 		return fmt.Errorf("custom-columns specification is required")
 	}
 
-	columns := parseColumnSpec(f.Spec)
-	if len(columns) == 0 {
-		return fmt.Errorf("no columns specified")
+	headers, rows, err := customColumnsTable(f.Spec, data)
+	if err != nil {
+		return err
 	}
+	return PrintAligned(w, headers, rows)
+}
 
-	// Convert data to JSON for processing
-	jsonData, err := json.Marshal(data)
+// customColumnsTable returns the headers and rows of the table of data with
+// the columns of a custom-columns specification. Lists have a row per item.
+func customColumnsTable(spec string, data interface{}) ([]string, [][]string, error) {
+	columns, err := parseColumnSpec(spec)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		return nil, nil, err
 	}
-
-	var dataMap map[string]interface{}
-	if err := json.Unmarshal(jsonData, &dataMap); err != nil {
-		return fmt.Errorf("failed to unmarshal data: %w", err)
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("no columns specified")
 	}
 
-	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	defer tw.Flush()
+	normalized, err := normalizeJSON(data)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Print headers
 	headers := make([]string, len(columns))
 	for i, col := range columns {
 		headers[i] = col.Header
 	}
-	fmt.Fprintln(tw, strings.Join(headers, "\t"))
 
 	// Check if data has items (list) or is a single resource
-	items, ok := dataMap["items"].([]interface{})
-	if !ok {
-		// Single resource
-		items = []interface{}{dataMap}
+	items := []interface{}{normalized}
+	if dataMap, ok := normalized.(map[string]interface{}); ok {
+		if list, ok := dataMap["items"].([]interface{}); ok {
+			items = list
+		}
 	}
 
-	// Print rows
+	// Several values of one column are comma-separated
+	rows := make([][]string, 0, len(items))
 	for _, item := range items {
 		row := make([]string, len(columns))
 		for i, col := range columns {
-			value := evaluateJSONPath(item, col.JSONPath)
-			row[i] = formatJSONPathValue(value)
+			var values []string
+			for _, value := range col.path.FindResults(item) {
+				values = append(values, FormatJSONPathValue(value))
+			}
+			row[i] = strings.Join(values, ",")
 		}
-		fmt.Fprintln(tw, strings.Join(row, "\t"))
+		rows = append(rows, row)
 	}
 
-	return nil
+	return headers, rows, nil
 }
 
 // parseColumnSpec parses a custom column specification string
 // Format: "NAME:.metadata.name,NAMESPACE:.metadata.namespace".
-func parseColumnSpec(spec string) []ColumnSpec {
+func parseColumnSpec(spec string) ([]ColumnSpec, error) {
 	var columns []ColumnSpec
 
-	parts := strings.Split(spec, ",")
-	for _, part := range parts {
+	for _, part := range splitTopLevel(spec, ',') {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
 
-		colonIdx := strings.Index(part, ":")
-		if colonIdx == -1 {
-			continue
+		header, jsonPath, ok := strings.Cut(part, ":")
+		header, jsonPath = strings.TrimSpace(header), strings.TrimSpace(jsonPath)
+		if !ok || header == "" || jsonPath == "" {
+			return nil, fmt.Errorf("invalid custom column %q: expected <header>:<jsonpath>", part)
 		}
 
-		header := strings.TrimSpace(part[:colonIdx])
-		jsonPath := strings.TrimSpace(part[colonIdx+1:])
-
-		if header != "" && jsonPath != "" {
-			columns = append(columns, ColumnSpec{
-				Header:   header,
-				JSONPath: jsonPath,
-			})
+		path, err := CompileJSONPathExpression(jsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom column %q: %w", header, err)
 		}
+		columns = append(columns, ColumnSpec{
+			Header:   header,
+			JSONPath: jsonPath,
+			path:     path,
+		})
 	}
 
-	return columns
+	return columns, nil
 }
 
 // ============================================================================
@@ -3,7 +3,9 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -206,3 +208,369 @@ func TestFormatValueNilHandling(t *testing.T) {
 	result = formatValue(reflect.ValueOf(&str))
 	assert.Equal(t, "test", result)
 }
+
+// jsonPathData is a list of resources as returned by the API.
+func jsonPathData() interface{} {
+	var data interface{}
+	_ = json.Unmarshal([]byte(`{
+		"kind": "List",
+		"items": [
+			{"metadata": {"name": "web", "labels": {"app.kubernetes.io/name": "shop"}},
+			 "spec": {"port": 443, "domains": ["a.example.com", "b.example.com"], "tls": true}},
+			{"metadata": {"name": "api"},
+			 "spec": {"port": 8080, "domains": ["api.example.com"], "tls": false}},
+			{"metadata": {"name": "db"},
+			 "spec": {"port": 5432, "domains": []}}
+		]
+	}`), &data)
+	return data
+}
+
+func TestJSONPathTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "field", template: "{.kind}", want: "List"},
+		{name: "relaxed", template: ".items[0].metadata.name", want: "web"},
+		{name: "root", template: "{$.items[1].metadata.name}", want: "api"},
+		{name: "wildcard", template: "{.items[*].metadata.name}", want: "web api db"},
+		{name: "negative index", template: "{.items[-1].metadata.name}", want: "db"},
+		{name: "index union", template: "{.items[0,2].metadata.name}", want: "web db"},
+		{name: "slice", template: "{.items[1:].metadata.name}", want: "api db"},
+		{name: "slice step", template: "{.items[::2].metadata.name}", want: "web db"},
+		{name: "key union", template: "{.items[0].metadata['name','missing']}", want: "web"},
+		{name: "quoted key", template: "{.items[0].metadata.labels['app.kubernetes.io/name']}", want: "shop"},
+		{name: "recursive descent", template: "{..name}", want: "web api db"},
+		{name: "string filter", template: `{.items[?(@.metadata.name=="api")].spec.port}`, want: "8080"},
+		{name: "numeric filter", template: "{.items[?(@.spec.port < 5000)].metadata.name}", want: "web"},
+		{name: "bool filter", template: "{.items[?(@.spec.tls == false)].metadata.name}", want: "api"},
+		{name: "not equal filter", template: "{.items[?(@.metadata.name != 'web')].metadata.name}", want: "api db"},
+		{name: "existence filter", template: "{.items[?(@.spec.tls)].metadata.name}", want: "web api"},
+		{name: "object as json", template: "{.items[1].spec.domains}", want: `["api.example.com"]`},
+		{name: "text and literals", template: `names: {.items[0].metadata.name}{"\t"}{.items[1].metadata.name}`, want: "names: web\tapi"},
+		{
+			name:     "range",
+			template: `{range .items[*]}{.metadata.name}:{.spec.port}{"\n"}{end}`,
+			want:     "web:443\napi:8080\ndb:5432\n",
+		},
+		{
+			name:     "nested range",
+			template: `{range .items[*]}{.metadata.name}={range .spec.domains[*]}{@},{end};{end}`,
+			want:     "web=a.example.com,b.example.com,;api=api.example.com,;db=;",
+		},
+		{name: "missing", template: "{.items[5].metadata.name}", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := CompileJSONPath(tt.template)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, j.Execute(&buf, jsonPathData()))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestCompileJSONPathErrors(t *testing.T) {
+	for _, template := range []string{
+		"{.items[0}",
+		"{range .items[*]}{.name}",
+		"{.name}{end}",
+		"{.items[?(.x == 1)]}",
+		"{.items[a:b]}",
+		"{.items[::0]}",
+		`{.items[?(@.x == "y)]}`,
+	} {
+		t.Run(template, func(t *testing.T) {
+			_, err := CompileJSONPath(template)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := CompileJSONPathExpression("{.a}{.b}")
+	assert.Error(t, err)
+}
+
+func TestEvaluateJSONPath(t *testing.T) {
+	data := jsonPathData()
+
+	tests := []struct {
+		name string
+		expr string
+		want interface{}
+	}{
+		{name: "definite", expr: ".items[1].spec.port", want: float64(8080)},
+		{name: "braces", expr: "{.items[0].metadata.name}", want: "web"},
+		{name: "definite missing", expr: ".items[1].spec.missing", want: nil},
+		{name: "wildcard", expr: ".items[*].spec.port", want: []interface{}{float64(443), float64(8080), float64(5432)}},
+		{name: "wildcard missing", expr: ".items[*].spec.missing", want: nil},
+		{name: "invalid", expr: ".items[", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EvaluateJSONPath(data, tt.expr))
+		})
+	}
+}
+
+func TestJSONPathFormatters(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewFormatter("jsonpath={.items[*].metadata.name}").Format(&buf, jsonPathData()))
+	assert.Equal(t, "web api db\n", buf.String())
+
+	buf.Reset()
+	formatter := NewFormatter("custom-columns=NAME:.metadata.name,DOMAINS:{.spec.domains[*]},FIRST:.spec.domains[0]")
+	require.NoError(t, formatter.Format(&buf, jsonPathData()))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"NAME", "DOMAINS", "FIRST"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"web", "a.example.com,b.example.com", "a.example.com"}, strings.Fields(lines[1]))
+
+	assert.Error(t, NewFormatter("custom-columns=NAME:.items[").Format(&buf, jsonPathData()))
+	assert.Error(t, NewFormatter("custom-columns=NAME").Format(&buf, jsonPathData()))
+}
+
+func TestJQFormatter(t *testing.T) {
+	type lb struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		raw     bool
+		data    interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "list names", expr: ".items[].metadata.name", data: jsonPathData(), want: "\"web\"\n\"api\"\n\"db\"\n"},
+		{name: "raw output", expr: ".items[].metadata.name", raw: true, data: jsonPathData(), want: "web\napi\ndb\n"},
+		{name: "raw output keeps non-strings", expr: ".items[0].spec.port", raw: true, data: jsonPathData(), want: "443\n"},
+		{name: "select", expr: `.items[] | select(.spec.tls) | .metadata.name`, raw: true, data: jsonPathData(), want: "web\n"},
+		{name: "construct object", expr: `{name: .items[1].metadata.name, n: (.items | length)}`, data: jsonPathData(), want: "{\n  \"n\": 3,\n  \"name\": \"api\"\n}\n"},
+		{name: "single resource", expr: ".name", raw: true, data: lb{Name: "a<b>", Port: 80}, want: "a<b>\n"},
+		{name: "no results", expr: "empty", data: jsonPathData(), want: ""},
+		{name: "halt", expr: `"x", halt, "y"`, raw: true, data: jsonPathData(), want: "x\n"},
+		{name: "parse error", expr: ".items[", data: jsonPathData(), wantErr: true},
+		{name: "runtime error", expr: `error("boom")`, data: jsonPathData(), wantErr: true},
+		{name: "missing expression", expr: "", data: jsonPathData(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewFormatter("jq=" + tt.expr).(*JQFormatter)
+			formatter.RawOutput = tt.raw
+			var buf bytes.Buffer
+			err := formatter.Format(&buf, tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTabularFormatter(t *testing.T) {
+	type row struct {
+		Name  string `json:"name"`
+		Notes string `json:"notes"`
+	}
+	rows := []row{
+		{Name: "web", Notes: `says "hi", twice`},
+		{Name: "api", Notes: "a|b\tc\nd"},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		noHeaders bool
+		data      interface{}
+		want      string
+	}{
+		{
+			name:   "csv quotes cells",
+			format: "csv",
+			data:   rows,
+			want:   "NAME,NOTES\nweb,\"says \"\"hi\"\", twice\"\napi,\"a|b\tc\nd\"\n",
+		},
+		{
+			name:   "tsv escapes tabs and newlines",
+			format: "tsv",
+			data:   rows,
+			want:   "NAME\tNOTES\nweb\tsays \"hi\", twice\napi\ta|b\\tc\\nd\n",
+		},
+		{
+			name:   "markdown escapes pipes",
+			format: "markdown",
+			data:   rows,
+			want:   "| NAME | NOTES |\n| --- | --- |\n| web | says \"hi\", twice |\n| api | a\\|b\tc<br>d |\n",
+		},
+		{
+			name:      "no headers",
+			format:    "csv",
+			noHeaders: true,
+			data:      rows[:1],
+			want:      "web,\"says \"\"hi\"\", twice\"\n",
+		},
+		{
+			name:      "markdown always has headers",
+			format:    "markdown",
+			noHeaders: true,
+			data:      []row{},
+			want:      "| NAME | NOTES |\n| --- | --- |\n",
+		},
+		{
+			name:   "custom columns",
+			format: "tsv=NAME:.metadata.name,DOMAINS:.spec.domains[*]",
+			data:   jsonPathData(),
+			want:   "NAME\tDOMAINS\nweb\ta.example.com,b.example.com\napi\tapi.example.com\ndb\t\n",
+		},
+		{
+			name:   "map",
+			format: "csv",
+			data:   map[string]int{"b": 2, "a": 1},
+			want:   "KEY,VALUE\na,1\nb,2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, IsTabularFormat(tt.format))
+			formatter := NewFormatter(tt.format).(*TabularFormatter)
+			formatter.NoHeaders = tt.noHeaders
+			var buf bytes.Buffer
+			require.NoError(t, formatter.Format(&buf, tt.data))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	assert.False(t, IsTabularFormat("custom-columns=NAME:.name"))
+	assert.Error(t, NewFormatter("csv=NAME").Format(&bytes.Buffer{}, rows))
+}
+
+// fakeTerminal makes files look like a terminal of the given width until
+// the test ends.
+func fakeTerminal(t *testing.T, width int) {
+	origIsTerminal, origSize := isTerminal, terminalSize
+	t.Cleanup(func() { isTerminal, terminalSize = origIsTerminal, origSize })
+	isTerminal = func(int) bool { return true }
+	terminalSize = func(int) (int, int, error) { return width, 24, nil }
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Cleanup(func() { _ = SetColorMode(ColorAuto) })
+	t.Setenv("TERM", "xterm")
+	stdout := os.Stdout
+
+	tests := []struct {
+		name     string
+		mode     string
+		noColor  string
+		terminal bool
+		want     bool
+	}{
+		{name: "auto on terminal", mode: ColorAuto, terminal: true, want: true},
+		{name: "auto when piped", mode: ColorAuto, want: false},
+		{name: "auto with NO_COLOR", mode: ColorAuto, noColor: "1", terminal: true, want: false},
+		{name: "always when piped", mode: ColorAlways, want: true},
+		{name: "always with NO_COLOR", mode: ColorAlways, noColor: "1", want: true},
+		{name: "never on terminal", mode: ColorNever, terminal: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.terminal {
+				fakeTerminal(t, 80)
+			}
+			t.Setenv("NO_COLOR", tt.noColor)
+			require.NoError(t, SetColorMode(tt.mode))
+			assert.Equal(t, tt.want, ColorEnabled(stdout))
+		})
+	}
+
+	// Buffers are never terminals
+	require.NoError(t, SetColorMode(ColorAuto))
+	assert.False(t, ColorEnabled(&bytes.Buffer{}))
+	assert.Equal(t, "ok", Colorize(&bytes.Buffer{}, Green, "ok"))
+	require.NoError(t, SetColorMode(ColorAlways))
+	assert.Equal(t, "\033[32mok\033[0m", Colorize(&bytes.Buffer{}, Green, "ok"))
+
+	assert.Error(t, SetColorMode("sometimes"))
+}
+
+func TestFitToWidth(t *testing.T) {
+	rows := [][]string{
+		{"NAME", "DOMAINS", "AGE"},
+		{"web", "a.example.com,b.example.com", "5d"},
+	}
+
+	tests := []struct {
+		name  string
+		width int
+		want  [][]string
+	}{
+		{
+			name:  "fits",
+			width: 80,
+			want:  rows,
+		},
+		{
+			name:  "truncates the widest column",
+			width: 25,
+			want: [][]string{
+				{"NAME", "DOMAINS", "AGE"},
+				{"web", "a.example.c...", "5d"},
+			},
+		},
+		{
+			name:  "keeps a minimum width",
+			width: 5,
+			want: [][]string{
+				{"NAME", "DOMAINS", "AGE"},
+				{"web", "a.exa...", "5d"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FitToWidth(rows, tt.width, 2))
+		})
+	}
+}
+
+func TestPrintAligned(t *testing.T) {
+	headers := []string{"NAME", "DOMAINS"}
+	rows := [][]string{{"web", "a.example.com,b.example.com"}}
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	read := func() string {
+		data, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		require.NoError(t, f.Truncate(0))
+		_, err = f.Seek(0, 0)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// Not a terminal: nothing is truncated
+	require.NoError(t, PrintAligned(f, headers, rows))
+	assert.Equal(t, "NAME  DOMAINS\nweb   a.example.com,b.example.com\n", read())
+
+	fakeTerminal(t, 20)
+	require.NoError(t, PrintAligned(f, headers, rows))
+	assert.Equal(t, "NAME  DOMAINS\nweb   a.example.c...\n", read())
+
+	t.Setenv("COLUMNS", "100")
+	require.NoError(t, PrintAligned(f, nil, rows))
+	assert.Equal(t, "web  a.example.com,b.example.com\n", read())
+}
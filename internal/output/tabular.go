@@ -0,0 +1,172 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Styles of the TabularFormatter.
+const (
+	StyleCSV      = "csv"
+	StyleTSV      = "tsv"
+	StyleMarkdown = "markdown"
+)
+
+// IsTabularFormat reports whether format is one of the tabular formats (csv,
+// tsv, markdown), with or without a column specification.
+func IsTabularFormat(format string) bool {
+	name, _, _ := strings.Cut(format, "=")
+	switch strings.ToLower(name) {
+	case StyleCSV, StyleTSV, StyleMarkdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// TabularFormatter formats output as CSV, TSV or a Markdown table, for
+// pasting into spreadsheets and documents. Cells are quoted or escaped as the
+// style requires, and never truncated.
+type TabularFormatter struct {
+	Style string
+	// Spec selects the columns in custom-columns syntax; if empty, the
+	// columns are derived from the data like the TableFormatter does
+	Spec string
+	// NoHeaders omits the header row; Markdown tables always have one
+	NoHeaders bool
+}
+
+// Format formats data as a table in the style of the formatter.
+func (f *TabularFormatter) Format(w io.Writer, data interface{}) error {
+	var (
+		headers []string
+		rows    [][]string
+		err     error
+	)
+	if f.Spec != "" {
+		headers, rows, err = customColumnsTable(f.Spec, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		headers, rows = (&TableFormatter{}).content(data)
+	}
+	return f.WriteTable(w, headers, rows)
+}
+
+// WriteTable writes headers and rows in the style of the formatter. A nil
+// headers slice writes the rows only, e.g. to continue a table.
+func (f *TabularFormatter) WriteTable(w io.Writer, headers []string, rows [][]string) error {
+	if f.NoHeaders && f.Style != StyleMarkdown {
+		headers = nil
+	}
+
+	switch f.Style {
+	case StyleCSV:
+		cw := csv.NewWriter(w)
+		if headers != nil {
+			if err := cw.Write(headers); err != nil {
+				return fmt.Errorf("failed to write csv: %w", err)
+			}
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+		return nil
+	case StyleTSV:
+		if headers != nil {
+			rows = append([][]string{headers}, rows...)
+		}
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = tsvEscaper.Replace(cell)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case StyleMarkdown:
+		if headers != nil {
+			separator := make([]string, len(headers))
+			for i := range separator {
+				separator[i] = "---"
+			}
+			rows = append([][]string{headers, separator}, rows...)
+		}
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownEscaper.Replace(cell)
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown table style %q", f.Style)
+	}
+}
+
+// tsvEscaper escapes the characters that would break a TSV record, using the
+// conventions of the text format of PostgreSQL and MySQL.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// markdownEscaper escapes the characters that would break a Markdown table
+// cell.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// content returns the headers and rows of the table of data. Slices of
+// structs become a row per element, a struct a single row and a map its
+// key/value pairs.
+func (f *TableFormatter) content(data interface{}) ([]string, [][]string) {
+	val := reflect.ValueOf(data)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	var rows [][]string
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		// The element type gives the headers of empty slices too
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = reflect.New(elem.Type().Elem()).Elem()
+		}
+		if val.Len() > 0 {
+			elem = val.Index(0)
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+		}
+		headers, keys := f.getHeadersAndKeys(elem)
+		for i := 0; i < val.Len(); i++ {
+			elem := val.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			rows = append(rows, f.extractRow(elem, keys))
+		}
+		return headers, rows
+	case reflect.Struct:
+		headers, keys := f.getHeadersAndKeys(val)
+		return headers, [][]string{f.extractRow(val, keys)}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			rows = append(rows, []string{fmt.Sprint(key.Interface()), fmt.Sprint(val.MapIndex(key).Interface())})
+		}
+		return []string{"KEY", "VALUE"}, rows
+	default:
+		return []string{"VALUE"}, [][]string{{fmt.Sprint(data)}}
+	}
+}
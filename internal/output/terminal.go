@@ -0,0 +1,181 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// Color modes of the --color flag.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Color is an ANSI color of terminal output.
+type Color string
+
+// Colors used in output.
+const (
+	Red    Color = "31"
+	Green  Color = "32"
+	Yellow Color = "33"
+)
+
+// minColumnWidth is the width below which columns are not truncated to fit
+// the terminal.
+const minColumnWidth = 8
+
+var colorMode = ColorAuto
+
+// Terminal queries, replaced in tests.
+var (
+	isTerminal   = term.IsTerminal
+	terminalSize = term.GetSize
+)
+
+// SetColorMode sets when output is colored: always, never, or auto (only on
+// terminals, unless NO_COLOR is set).
+func SetColorMode(mode string) error {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		colorMode = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid color mode %q: must be auto, always or never", mode)
+	}
+}
+
+// IsTerminal reports whether w is a terminal. Output that is piped or
+// redirected gets no decoration.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(int(f.Fd()))
+}
+
+// ColorEnabled reports whether output written to w is colored. In auto mode
+// that is the case on terminals, unless NO_COLOR (see no-color.org) is set
+// or TERM is dumb.
+func ColorEnabled(w io.Writer) bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// Colorize returns s in color c if output written to w is colored.
+func Colorize(w io.Writer, c Color, s string) string {
+	if !ColorEnabled(w) {
+		return s
+	}
+	return "\033[" + string(c) + "m" + s + "\033[0m"
+}
+
+// TerminalWidth returns the width of the terminal w is written to, or 0 if
+// w is not a terminal. COLUMNS overrides the width reported by the terminal.
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !isTerminal(int(f.Fd())) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	width, _, err := terminalSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// PrintAligned writes headers and rows as an aligned table, the layout of
+// the table output. On terminals, the widest columns are truncated so that
+// rows fit the terminal width. A nil headers slice writes the rows only.
+func PrintAligned(w io.Writer, headers []string, rows [][]string) error {
+	const padding = 2
+
+	lines := rows
+	if headers != nil {
+		lines = append([][]string{headers}, rows...)
+	}
+	if width := TerminalWidth(w); width > 0 {
+		lines = FitToWidth(lines, width, padding)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+	for _, line := range lines {
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+	return tw.Flush()
+}
+
+// FitToWidth truncates the cells of the widest columns of rows, marking them
+// with "...", until the rows fit in width when their columns are separated
+// by padding spaces. Columns are not truncated below minColumnWidth, so rows
+// with many columns may still be wider.
+func FitToWidth(rows [][]string, width, padding int) [][]string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	if len(widths) == 0 {
+		return rows
+	}
+
+	total := padding * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	limits := append([]int(nil), widths...)
+	for total > width {
+		widest := 0
+		for i := range limits {
+			if limits[i] > limits[widest] {
+				widest = i
+			}
+		}
+		if limits[widest] <= minColumnWidth {
+			break
+		}
+		limits[widest]--
+		total--
+	}
+
+	fitted := make([][]string, len(rows))
+	for r, row := range rows {
+		fitted[r] = make([]string, len(row))
+		for i, cell := range row {
+			fitted[r][i] = truncate(cell, limits[i])
+		}
+	}
+	return fitted
+}
+
+// truncate shortens s to width runes, ending it with "...".
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,10 +21,11 @@ import (
 
 // Client is the F5XC API client.
 type Client struct {
-	httpClient    *http.Client
-	baseURL       string
-	authenticator auth.Authenticator
-	debug         bool
+	httpClient     *http.Client
+	baseURL        string
+	authenticator  auth.Authenticator
+	debug          bool
+	passwordPrompt auth.PasswordFunc
 }
 
 // ClientOption is a function that configures the client.
@@ -36,13 +38,31 @@ func WithDebug(debug bool) ClientOption {
 	}
 }
 
+// WithPasswordPrompt sets the function used to ask for P12 passwords and
+// private key passphrases that are not otherwise configured.
+func WithPasswordPrompt(prompt auth.PasswordFunc) ClientOption {
+	return func(c *Client) {
+		c.passwordPrompt = prompt
+	}
+}
+
 // NewClient creates a new API client.
-func NewClient(cfg *config.Config, creds *config.Credentials) (*Client, error) {
+func NewClient(cfg *config.Config, creds *config.Credentials, opts ...ClientOption) (*Client, error) {
 	profile := cfg.GetCurrentProfile()
 	if profile == nil {
 		return nil, fmt.Errorf("no profile configured")
 	}
 
+	client := &Client{}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	var profileCreds config.ProfileCredentials
+	if creds != nil {
+		profileCreds = creds.Profiles[cfg.CurrentUser()]
+	}
+
 	var authenticator auth.Authenticator
 
 	// Determine authentication method
@@ -51,30 +71,66 @@ func NewClient(cfg *config.Config, creds *config.Credentials) (*Client, error) {
 		if profile.CertFile == "" || profile.KeyFile == "" {
 			return nil, fmt.Errorf("certificate and key files required for certificate authentication")
 		}
-		authenticator = auth.NewCertAuth(profile.CertFile, profile.KeyFile)
+		// Key passphrase comes from credentials, then the environment
+		keyPassword := profileCreds.KeyPassword
+		if keyPassword == "" {
+			var err error
+			if keyPassword, err = auth.ReadSecretEnv("F5XC_KEY_PASSWORD"); err != nil {
+				return nil, err
+			}
+		}
+		certAuth := auth.NewCertAuth(profile.CertFile, profile.KeyFile)
+		certAuth.KeyPassword = keyPassword
+		certAuth.PasswordPrompt = client.passwordPrompt
+		certAuth.ExpiryWarningWindow = expiryWarningWindow(profile.CertExpiryWarningDays)
+		authenticator = certAuth
 	case "p12":
 		if profile.P12File == "" {
 			return nil, fmt.Errorf("P12 file required for P12 certificate authentication")
 		}
-		// P12 password is retrieved from credentials
-		var p12Password string
-		if creds != nil {
-			if profileCreds, ok := creds.Profiles[cfg.CurrentProfile]; ok {
-				p12Password = profileCreds.P12Password
+		// P12 password comes from credentials, then the environment
+		p12Password := profileCreds.P12Password
+		if p12Password == "" {
+			var err error
+			if p12Password, err = auth.ReadSecretEnv("F5XC_P12_PASSWORD"); err != nil {
+				return nil, err
 			}
 		}
-		authenticator = auth.NewP12Auth(profile.P12File, p12Password)
+		p12Auth := auth.NewP12Auth(profile.P12File, p12Password)
+		p12Auth.PasswordPrompt = client.passwordPrompt
+		p12Auth.ExpiryWarningWindow = expiryWarningWindow(profile.CertExpiryWarningDays)
+		authenticator = p12Auth
+	case "oidc-federation":
+		if profile.OIDCExchangeURL == "" {
+			return nil, fmt.Errorf("oidc-exchange-url required for OIDC federation authentication")
+		}
+		if profile.OIDCTokenFile == "" && profile.OIDCTokenEnv == "" {
+			return nil, fmt.Errorf("oidc-token-file or oidc-token-env required for OIDC federation authentication")
+		}
+		authenticator = auth.NewOIDCFederationAuth(profile.OIDCTokenFile, profile.OIDCTokenEnv, profile.OIDCExchangeURL, profile.OIDCAudience)
 	default:
 		if creds == nil {
 			return nil, fmt.Errorf("credentials required for API token authentication")
 		}
-		profileCreds, ok := creds.Profiles[cfg.CurrentProfile]
-		if !ok || profileCreds.APIToken == "" {
+		if profileCreds.APIToken == "" {
 			return nil, fmt.Errorf("no API token found for profile %q", cfg.CurrentProfile)
 		}
 		authenticator = auth.NewTokenAuth(profileCreds.APIToken)
 	}
 
+	// Exchange the certificate for a cached short-lived token if requested
+	if profile.TokenExchange {
+		switch profile.AuthMethod {
+		case "certificate", "p12":
+			authenticator = auth.NewExchangeAuth(authenticator, profile.APIURL, &credentialsTokenCache{
+				user: cfg.CurrentUser(),
+				path: cfg.CredentialsPath(),
+			})
+		default:
+			return nil, fmt.Errorf("token-exchange requires certificate or p12 authentication")
+		}
+	}
+
 	// Create retryable HTTP client
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = 3
@@ -116,11 +172,11 @@ func NewClient(cfg *config.Config, creds *config.Credentials) (*Client, error) {
 		retryClient.HTTPClient.Transport = httpClient.Transport
 	}
 
-	return &Client{
-		httpClient:    retryClient.StandardClient(),
-		baseURL:       strings.TrimSuffix(profile.APIURL, "/"),
-		authenticator: authenticator,
-	}, nil
+	client.httpClient = retryClient.StandardClient()
+	client.baseURL = strings.TrimSuffix(profile.APIURL, "/")
+	client.authenticator = authenticator
+
+	return client, nil
 }
 
 // NewClientFromEnv creates a new API client from environment variables.
@@ -130,36 +186,78 @@ func NewClient(cfg *config.Config, creds *config.Credentials) (*Client, error) {
 //   - F5XC_API_TOKEN: API token for token-based auth
 //   - F5XC_API_P12_FILE: Path to P12 certificate file
 //   - F5XC_P12_PASSWORD: Password for P12 file
+//   - F5XC_P12_PASSWORD_FILE: File containing the password for the P12 file
 //   - F5XC_CERT_FILE: Path to certificate file (PEM)
 //   - F5XC_KEY_FILE: Path to key file (PEM)
+//   - F5XC_KEY_PASSWORD: Passphrase for an encrypted key file
+//   - F5XC_KEY_PASSWORD_FILE: File containing the key passphrase
+//   - F5XC_OIDC_EXCHANGE_URL: Token-exchange endpoint for OIDC federation
+//   - F5XC_OIDC_TOKEN_FILE: File containing the CI-issued OIDC JWT
+//   - F5XC_OIDC_TOKEN_ENV: Environment variable holding the OIDC JWT
+//   - F5XC_OIDC_AUDIENCE: Audience passed to the token-exchange endpoint
+//   - F5XC_CERT_EXPIRY_WARNING_DAYS: Days before certificate expiry to warn
 func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
 	apiURL := os.Getenv("F5XC_API_URL")
 	if apiURL == "" {
 		return nil, fmt.Errorf("F5XC_API_URL environment variable is required")
 	}
 
+	client := &Client{}
+	for _, opt := range opts {
+		opt(client)
+	}
+
 	var authenticator auth.Authenticator
 
 	// Check for P12 auth first
 	p12File := os.Getenv("F5XC_API_P12_FILE")
-	p12Password := os.Getenv("F5XC_P12_PASSWORD")
+	p12Password, err := auth.ReadSecretEnv("F5XC_P12_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
 
 	// Check for certificate auth
 	certFile := os.Getenv("F5XC_CERT_FILE")
 	keyFile := os.Getenv("F5XC_KEY_FILE")
+	keyPassword, err := auth.ReadSecretEnv("F5XC_KEY_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for OIDC federation
+	oidcExchangeURL := os.Getenv("F5XC_OIDC_EXCHANGE_URL")
 
 	// Check for token auth
 	apiToken := os.Getenv("F5XC_API_TOKEN")
 
+	var warningDays int
+	if v := os.Getenv("F5XC_CERT_EXPIRY_WARNING_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid F5XC_CERT_EXPIRY_WARNING_DAYS: %q is not an integer", v)
+		}
+		warningDays = days
+	}
+
 	switch {
 	case p12File != "":
-		authenticator = auth.NewP12Auth(p12File, p12Password)
+		p12Auth := auth.NewP12Auth(p12File, p12Password)
+		p12Auth.PasswordPrompt = client.passwordPrompt
+		p12Auth.ExpiryWarningWindow = expiryWarningWindow(warningDays)
+		authenticator = p12Auth
 	case certFile != "" && keyFile != "":
-		authenticator = auth.NewCertAuth(certFile, keyFile)
+		certAuth := auth.NewCertAuth(certFile, keyFile)
+		certAuth.KeyPassword = keyPassword
+		certAuth.PasswordPrompt = client.passwordPrompt
+		certAuth.ExpiryWarningWindow = expiryWarningWindow(warningDays)
+		authenticator = certAuth
+	case oidcExchangeURL != "":
+		authenticator = auth.NewOIDCFederationAuth(os.Getenv("F5XC_OIDC_TOKEN_FILE"), os.Getenv("F5XC_OIDC_TOKEN_ENV"),
+			oidcExchangeURL, os.Getenv("F5XC_OIDC_AUDIENCE"))
 	case apiToken != "":
 		authenticator = auth.NewTokenAuth(apiToken)
 	default:
-		return nil, fmt.Errorf("no authentication method configured: set F5XC_API_TOKEN, F5XC_API_P12_FILE, or F5XC_CERT_FILE/F5XC_KEY_FILE")
+		return nil, fmt.Errorf("no authentication method configured: set F5XC_API_TOKEN, F5XC_API_P12_FILE, F5XC_CERT_FILE/F5XC_KEY_FILE, or F5XC_OIDC_EXCHANGE_URL")
 	}
 
 	// Create retryable HTTP client
@@ -195,18 +293,33 @@ func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
 		retryClient.HTTPClient.Transport = httpClient.Transport
 	}
 
-	client := &Client{
-		httpClient:    retryClient.StandardClient(),
-		baseURL:       strings.TrimSuffix(apiURL, "/"),
-		authenticator: authenticator,
-	}
+	client.httpClient = retryClient.StandardClient()
+	client.baseURL = strings.TrimSuffix(apiURL, "/")
+	client.authenticator = authenticator
 
-	// Apply options
-	for _, opt := range opts {
-		opt(client)
+	return client, nil
+}
+
+// expiryWarningWindow converts a configured number of days into a certificate
+// expiry warning window. Zero selects the default window.
+func expiryWarningWindow(days int) time.Duration {
+	if days == 0 {
+		return auth.DefaultExpiryWarningWindow
 	}
+	return time.Duration(days) * 24 * time.Hour
+}
 
-	return client, nil
+// BaseURL returns the API base URL requests are sent to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// WithBaseURL returns a copy of the client that sends requests to baseURL
+// with the same credentials. It is used to act in a delegated tenant.
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	clone := *c
+	clone.baseURL = strings.TrimSuffix(baseURL, "/")
+	return &clone
 }
 
 // Request represents an API request.
@@ -263,7 +376,10 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 
 	// Add authentication
 	token, err := c.authenticator.GetToken()
-	if err == nil && token != "" {
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	if token != "" {
 		httpReq.Header.Set("Authorization", "APIToken "+token)
 	}
 
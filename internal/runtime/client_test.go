@@ -3,9 +3,12 @@ package runtime
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/f5/f5xcctl/internal/auth"
+	"github.com/f5/f5xcctl/internal/config"
 )
 
 // mockAuthenticator for testing.
@@ -304,6 +308,20 @@ func TestClient_Do_WithHeaders(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestClient_Do_TokenError(t *testing.T) {
+	requests := 0
+	client, server := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+	defer server.Close()
+	client.authenticator = &mockAuthenticator{err: errors.New("token exchange failed: status 403")}
+
+	_, err := client.Get(context.Background(), "/api/test", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get token: token exchange failed")
+	assert.Equal(t, 0, requests)
+}
+
 func TestClient_Do_ContextCancellation(t *testing.T) {
 	client, server := testClient(t, func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond)
@@ -348,6 +366,57 @@ func TestWithDebug(t *testing.T) {
 	assert.True(t, client.debug)
 }
 
+func TestNewClient_OIDCFederation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "ci-jwt", r.PostForm.Get("subject_token"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "federated-token",
+				"expires_in":   3600,
+			})
+		default:
+			assert.Equal(t, "APIToken federated-token", r.Header.Get("Authorization"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("CI_OIDC_TOKEN", "ci-jwt")
+	cfg := &config.Config{
+		CurrentProfile: "ci",
+		Profiles: map[string]config.Profile{
+			"ci": {
+				APIURL:          server.URL,
+				AuthMethod:      "oidc-federation",
+				OIDCTokenEnv:    "CI_OIDC_TOKEN",
+				OIDCExchangeURL: server.URL + "/token",
+			},
+		},
+	}
+
+	client, err := NewClient(cfg, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/api/web/namespaces", nil)
+	require.NoError(t, err)
+	assert.True(t, resp.IsSuccess())
+}
+
+func TestNewClient_OIDCFederationMissingSettings(t *testing.T) {
+	cfg := &config.Config{
+		CurrentProfile: "ci",
+		Profiles: map[string]config.Profile{
+			"ci": {AuthMethod: "oidc-federation", OIDCTokenEnv: "CI_OIDC_TOKEN"},
+		},
+	}
+
+	_, err := NewClient(cfg, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "oidc-exchange-url")
+}
+
 func TestRequest_Struct(t *testing.T) {
 	req := Request{
 		Method:      http.MethodPost,
@@ -379,3 +448,24 @@ func TestResponse_Struct(t *testing.T) {
 	assert.Equal(t, "application/json", resp.Headers.Get("Content-Type"))
 	assert.Equal(t, `{"test":true}`, string(resp.Body))
 }
+
+func TestCredentialsTokenCache_Save(t *testing.T) {
+	token := &auth.TokenResponse{AccessToken: "session-token", ExpiresAt: time.Now().Add(time.Hour)}
+
+	// A missing credentials file is created
+	path := filepath.Join(t.TempDir(), "credentials")
+	cache := &credentialsTokenCache{user: "cert", path: path}
+	require.NoError(t, cache.Save(token))
+	cached, err := cache.Load()
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, "session-token", cached.AccessToken)
+
+	// A credentials file that cannot be parsed is never overwritten
+	corrupt := []byte("profiles: [not: a map\n")
+	require.NoError(t, os.WriteFile(path, corrupt, 0o600))
+	assert.Error(t, cache.Save(token))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, data)
+}
@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/f5/f5xcctl/internal/auth"
+	"github.com/f5/f5xcctl/internal/config"
+)
+
+// credentialsTokenCache stores exchanged session tokens per user in the
+// credentials file.
+type credentialsTokenCache struct {
+	user string
+	path string // credentials file
+}
+
+// Load returns the cached session token for the user, if any.
+func (c *credentialsTokenCache) Load() (*auth.TokenResponse, error) {
+	creds, err := config.LoadCredentialsFrom(c.path)
+	if err != nil {
+		return nil, nil //nolint:nilerr // a missing credentials file just means no cached token
+	}
+
+	profileCreds := creds.Profiles[c.user]
+	if profileCreds.SessionToken == "" {
+		return nil, nil
+	}
+
+	return &auth.TokenResponse{
+		AccessToken:    profileCreds.SessionToken,
+		TokenType:      "APIToken",
+		ExpiresAt:      profileCreds.SessionExpiresAt,
+		CredentialName: profileCreds.SessionCredential,
+	}, nil
+}
+
+// Save stores the session token for the user. A credentials file that
+// cannot be read is left alone rather than replaced, so that the tokens and
+// passwords of other profiles are never lost.
+func (c *credentialsTokenCache) Save(token *auth.TokenResponse) error {
+	creds, err := config.LoadCredentialsFrom(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		creds = config.NewCredentials(c.path)
+	} else if err != nil {
+		return fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	profileCreds := creds.Profiles[c.user]
+	profileCreds.SessionToken = token.AccessToken
+	profileCreds.SessionExpiresAt = token.ExpiresAt
+	profileCreds.SessionCredential = token.CredentialName
+	creds.Profiles[c.user] = profileCreds
+
+	return config.SaveCredentials(creds)
+}